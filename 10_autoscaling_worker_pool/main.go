@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// printStats prints a one-line snapshot of the pool
+func printStats(label string, s workerpool.Stats) {
+	fmt.Printf("[%s] workers=%d busy=%d queued=%d p95=%v scale-ups=%d scale-downs=%d\n",
+		label, s.Workers, s.Busy, s.QueueDepth, s.LatencyP95.Round(time.Millisecond),
+		s.ScaleUps, s.ScaleDowns)
+}
+
+func main() {
+	fmt.Println("=== Autoscaling Worker Pool Example ===")
+
+	// Start with 1 worker; grow up to 5 when more than 2 jobs are waiting,
+	// and retire workers that stay idle for 500ms
+	pool := workerpool.New(1, func(ctx context.Context, jobID int) (int, error) {
+		time.Sleep(200 * time.Millisecond) // Simulate work
+		return jobID, nil
+	},
+		workerpool.WithQueueSize(50),
+		workerpool.WithAutoscale(workerpool.Autoscale{
+			MinWorkers:     1,
+			MaxWorkers:     5,
+			QueueThreshold: 2,
+			IdleTimeout:    500 * time.Millisecond,
+			CoolDown:       100 * time.Millisecond,
+			Interval:       50 * time.Millisecond,
+		}),
+		workerpool.WithEvents(func(e workerpool.Event) {
+			fmt.Printf("  event: %-10s workers=%d (%s)\n", e.Type, e.Workers, e.Reason)
+		}),
+	)
+
+	// Burst of 30 jobs: the queue backs up and the pool scales up
+	fmt.Println("\nSubmitting a burst of 30 jobs...")
+	ctx := context.Background()
+	futures := make([]*workerpool.Future[int], 0, 30)
+	for j := 1; j <= 30; j++ {
+		future, err := pool.Submit(ctx, j)
+		if err != nil {
+			fmt.Printf("Failed to submit job %d: %v\n", j, err)
+			continue
+		}
+		futures = append(futures, future)
+	}
+	printStats("burst", pool.Stats())
+
+	for _, f := range futures {
+		f.Wait()
+	}
+	printStats("done", pool.Stats())
+
+	// Quiet period: idle workers are retired back down to MinWorkers
+	fmt.Println("\nWaiting while the pool is idle...")
+	time.Sleep(1500 * time.Millisecond)
+	printStats("idle", pool.Stats())
+
+	pool.Close()
+	fmt.Println("All jobs completed!")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// job is a named unit of work with a priority
+type job struct {
+	name     string
+	priority int
+	delay    time.Duration // how long to wait before submitting the job
+}
+
+// runScenario submits jobs to a single-worker pool while the worker is busy,
+// then lets the worker go and prints the order the jobs ran in
+func runScenario(jobs []job, opts ...workerpool.Option) {
+	gate := make(chan struct{})
+	pool := workerpool.New(1, func(ctx context.Context, j job) (struct{}, error) {
+		if j.name == "blocker" {
+			<-gate // Hold the only worker until every job is queued
+			return struct{}{}, nil
+		}
+		fmt.Printf("  running %-6s (priority %d)\n", j.name, j.priority)
+		return struct{}{}, nil
+	}, append(opts, workerpool.WithQueueSize(len(jobs)))...)
+
+	// Occupy the worker so the following jobs have to wait in the queue
+	ctx := context.Background()
+	pool.Submit(ctx, job{name: "blocker"})
+	time.Sleep(10 * time.Millisecond)
+
+	for _, j := range jobs {
+		time.Sleep(j.delay)
+		if _, err := pool.Submit(ctx, j, workerpool.WithPriority(j.priority)); err != nil {
+			fmt.Printf("Failed to submit %s: %v\n", j.name, err)
+		}
+	}
+
+	close(gate)
+	pool.Close()
+}
+
+func main() {
+	fmt.Println("=== Priority Worker Pool Example ===")
+
+	// Scenario 1: higher priority first, FIFO between equal priorities
+	fmt.Println("\nScenario 1: strict priority")
+	runScenario([]job{
+		{name: "bulk-1", priority: workerpool.PriorityLow},
+		{name: "normal", priority: workerpool.PriorityNormal},
+		{name: "bulk-2", priority: workerpool.PriorityLow},
+		{name: "urgent", priority: workerpool.PriorityHigh},
+	})
+
+	// Scenario 2: without aging, an old low-priority job still waits behind
+	// a newer high-priority one
+	fmt.Println("\nScenario 2: no aging")
+	runScenario([]job{
+		{name: "bulk", priority: workerpool.PriorityLow},
+		{name: "urgent", priority: workerpool.PriorityHigh, delay: 300 * time.Millisecond},
+	})
+
+	// Scenario 3: with aging (1 level per 10ms) the bulk job has gained ~30
+	// levels after 300ms, so it now runs before the newer urgent job
+	fmt.Println("\nScenario 3: aging prevents starvation")
+	runScenario([]job{
+		{name: "bulk", priority: workerpool.PriorityLow},
+		{name: "urgent", priority: workerpool.PriorityHigh, delay: 300 * time.Millisecond},
+	}, workerpool.WithAging(10*time.Millisecond))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// ErrTimeout simulates a temporary failure of a downstream service
+var ErrTimeout = errors.New("downstream timeout")
+
+// ErrInvalid simulates a bad input that no retry can fix
+var ErrInvalid = errors.New("invalid order")
+
+func main() {
+	fmt.Println("=== Retry and Dead-Letter Queue Example ===")
+
+	var downstreamUp atomic.Bool
+	var mu sync.Mutex
+	attempts := make(map[int]int)
+
+	// Orders divisible by 4 are invalid, orders divisible by 3 only succeed
+	// once the downstream service is back up, the rest fail once then succeed
+	pool := workerpool.New(3, func(ctx context.Context, orderID int) (string, error) {
+		mu.Lock()
+		attempts[orderID]++
+		attempt := attempts[orderID]
+		mu.Unlock()
+
+		switch {
+		case orderID%4 == 0:
+			return "", workerpool.Permanent(ErrInvalid)
+		case orderID%3 == 0 && !downstreamUp.Load():
+			return "", ErrTimeout
+		case attempt == 1:
+			return "", ErrTimeout
+		}
+		return fmt.Sprintf("order %d shipped (attempt %d)", orderID, attempt), nil
+	},
+		workerpool.WithQueueSize(20),
+		workerpool.WithRetry(workerpool.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 50 * time.Millisecond,
+			MaxBackoff:     500 * time.Millisecond,
+			Multiplier:     2,
+			Jitter:         0.2,
+		}),
+		workerpool.WithDeadLetters(100),
+	)
+	defer pool.Close()
+
+	// Submit orders and wait for every result
+	ctx := context.Background()
+	var futures []*workerpool.Future[string]
+	for orderID := 1; orderID <= 10; orderID++ {
+		future, err := pool.Submit(ctx, orderID)
+		if err != nil {
+			fmt.Printf("Failed to submit order %d: %v\n", orderID, err)
+			continue
+		}
+		futures = append(futures, future)
+	}
+	for _, f := range futures {
+		if result, err := f.Wait(); err != nil {
+			fmt.Printf("❌ job %d failed: %v\n", f.ID(), err)
+		} else {
+			fmt.Printf("✅ %s\n", result)
+		}
+	}
+
+	// Inspect what ended up in the dead-letter queue
+	fmt.Println("\nDead letters:")
+	for _, dl := range pool.DeadLetters().List() {
+		fmt.Printf("  job %d (order %d) after %d attempt(s): %v\n", dl.ID, dl.Job, dl.Attempts, dl.Err)
+	}
+
+	// The downstream service recovers: requeue the jobs that only timed out
+	fmt.Println("\nDownstream is back, requeueing timed out orders...")
+	downstreamUp.Store(true)
+	for _, dl := range pool.DeadLetters().List() {
+		if workerpool.IsPermanent(dl.Err) {
+			continue
+		}
+		future, err := pool.Requeue(ctx, dl.ID)
+		if err != nil {
+			fmt.Printf("Failed to requeue job %d: %v\n", dl.ID, err)
+			continue
+		}
+		if result, err := future.Wait(); err != nil {
+			fmt.Printf("❌ order %d failed again: %v\n", dl.Job, err)
+		} else {
+			fmt.Printf("✅ %s\n", result)
+		}
+	}
+
+	fmt.Printf("\nDead letters left: %d (invalid orders)\n", pool.DeadLetters().Len())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+func main() {
+	fmt.Println("=== Panic Isolation and Job Timeout Example ===")
+
+	// Job 2 panics, job 4 ignores its context and hangs, job 6 is slow but
+	// honours its context; every other job finishes quickly
+	pool := workerpool.New(2, func(ctx context.Context, jobID int) (int, error) {
+		switch jobID {
+		case 2:
+			var m map[string]int
+			m["boom"] = 1 // panic: assignment to entry in nil map
+		case 4:
+			time.Sleep(2 * time.Second) // Stuck job that never checks ctx
+		case 6:
+			select {
+			case <-time.After(2 * time.Second):
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		return jobID * 10, nil
+	},
+		workerpool.WithQueueSize(10),
+		workerpool.WithJobTimeout(300*time.Millisecond),
+		workerpool.WithEvents(func(e workerpool.Event) {
+			fmt.Printf("  event: %s (%s)\n", e.Type, e.Reason)
+		}),
+	)
+	defer pool.Close()
+
+	ctx := context.Background()
+	var futures []*workerpool.Future[int]
+	for j := 1; j <= 8; j++ {
+		future, err := pool.Submit(ctx, j)
+		if err != nil {
+			fmt.Printf("Failed to submit job %d: %v\n", j, err)
+			continue
+		}
+		futures = append(futures, future)
+	}
+
+	// A panic or timeout only fails its own job; the rest keep running
+	for _, f := range futures {
+		result, err := f.Wait()
+		var panicErr *workerpool.PanicError
+		switch {
+		case errors.As(err, &panicErr):
+			fmt.Printf("💥 job %d panicked: %v (stack trace: %d lines)\n",
+				f.ID(), panicErr.Value, strings.Count(string(panicErr.Stack), "\n"))
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Printf("⏰ job %d timed out after %v\n", f.ID(), f.Duration().Round(time.Millisecond))
+		case err != nil:
+			fmt.Printf("❌ job %d failed: %v\n", f.ID(), err)
+		default:
+			fmt.Printf("✅ job %d -> %d\n", f.ID(), result)
+		}
+	}
+
+	stats := pool.Stats()
+	fmt.Printf("\nWorkers: %d, replaced after overrun: %d\n", stats.Workers, stats.Replaced)
+}
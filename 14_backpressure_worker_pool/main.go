@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// runPolicy pushes a fast stream of jobs into a small pool and shows what
+// the overflow policy did with each of them
+func runPolicy(policy workerpool.OverflowPolicy) {
+	fmt.Printf("\n--- Policy: %s ---\n", policy)
+
+	// 1 worker and room for 2 queued jobs; each job takes 100ms
+	pool := workerpool.New(1, func(ctx context.Context, jobID int) (int, error) {
+		time.Sleep(100 * time.Millisecond) // Simulate work
+		return jobID, nil
+	}, workerpool.WithQueueSize(2), workerpool.WithOverflow(policy))
+
+	// Produce 6 jobs, one every 20ms: faster than the pool can keep up
+	ctx := context.Background()
+	start := time.Now()
+	var futures []*workerpool.Future[int]
+	for j := 1; j <= 6; j++ {
+		future, err := pool.Submit(ctx, j)
+		if err != nil {
+			fmt.Printf("  job %d rejected: %v\n", j, err)
+		} else {
+			futures = append(futures, future)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	fmt.Printf("  producer finished after %v\n", time.Since(start).Round(10*time.Millisecond))
+
+	var done, dropped []string
+	for _, f := range futures {
+		result, err := f.Wait()
+		switch {
+		case errors.Is(err, workerpool.ErrDropped):
+			dropped = append(dropped, fmt.Sprint(f.ID()))
+		case err == nil:
+			done = append(done, fmt.Sprint(result))
+		}
+	}
+	fmt.Printf("  completed: [%s]  dropped: [%s]\n", strings.Join(done, " "), strings.Join(dropped, " "))
+
+	pool.Close()
+	s := pool.Stats()
+	fmt.Printf("  stats: blocked=%d rejected=%d dropped=%d caller-runs=%d\n",
+		s.Blocked, s.Rejected, s.Dropped, s.CallerRuns)
+}
+
+func main() {
+	fmt.Println("=== Backpressure Policies Example ===")
+
+	for _, policy := range []workerpool.OverflowPolicy{
+		workerpool.OverflowBlock,
+		workerpool.OverflowReject,
+		workerpool.OverflowDropOldest,
+		workerpool.OverflowDropNewest,
+		workerpool.OverflowCallerRuns,
+	} {
+		runPolicy(policy)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// statsHandler returns the pool's Stats snapshot as JSON
+func statsHandler(pool *workerpool.Pool[int, int]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pool.Stats())
+	}
+}
+
+// produce keeps submitting jobs so the metrics have something to show
+func produce(pool *workerpool.Pool[int, int]) {
+	ctx := context.Background()
+	for jobID := 1; ; jobID++ {
+		// A full queue is rejected and counted; only a closed pool stops us
+		if _, err := pool.Submit(ctx, jobID); errors.Is(err, workerpool.ErrPoolClosed) {
+			return
+		}
+		time.Sleep(time.Duration(rand.IntN(100)) * time.Millisecond)
+	}
+}
+
+func main() {
+	// Jobs take 10-500ms and roughly 1 in 10 fails, then is retried once
+	pool := workerpool.New(4, func(ctx context.Context, jobID int) (int, error) {
+		time.Sleep(time.Duration(10+rand.IntN(490)) * time.Millisecond) // Simulate work
+		if rand.IntN(10) == 0 {
+			return 0, errors.New("random failure")
+		}
+		return jobID * 2, nil
+	},
+		workerpool.WithName("demo"),
+		workerpool.WithQueueSize(20),
+		workerpool.WithOverflow(workerpool.OverflowReject),
+		workerpool.WithRetry(workerpool.RetryPolicy{MaxAttempts: 2, InitialBackoff: 100 * time.Millisecond}),
+	)
+	defer pool.Close()
+
+	go produce(pool)
+
+	// Register handlers
+	http.Handle("/metrics", pool.MetricsHandler())
+	http.HandleFunc("/stats", statsHandler(pool))
+
+	// Start server
+	fmt.Println("=== Worker Pool Metrics Server ===")
+	fmt.Println("Server starting on http://localhost:8080")
+	fmt.Println("Endpoints:")
+	fmt.Println("  GET /metrics  Prometheus text format")
+	fmt.Println("  GET /stats    Stats snapshot as JSON")
+	fmt.Println("\nExample usage with curl:")
+	fmt.Println("  curl http://localhost:8080/metrics")
+	fmt.Println("\nPress Ctrl+C to stop the server")
+
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Job is the unit of work stored in the journal (must be JSON encodable)
+type Job struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+const numJobs = 10
+
+// runWorker is the child process: it opens the journal, optionally submits
+// new jobs, and prints "done <id>" for every job it finishes
+func runWorker(journalPath string, submit bool) error {
+	journal, err := workerpool.OpenJournal(journalPath)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	pool := workerpool.New(2, func(ctx context.Context, job Job) (string, error) {
+		time.Sleep(300 * time.Millisecond) // Simulate work
+		fmt.Printf("done %d\n", job.ID)
+		return job.Name, nil
+	}, workerpool.WithQueueSize(numJobs), workerpool.WithJournal(journal))
+
+	fmt.Printf("replayed %d\n", len(pool.Recovered()))
+	if submit {
+		for id := 1; id <= numJobs; id++ {
+			if _, err := pool.Submit(context.Background(), Job{ID: id, Name: fmt.Sprintf("job-%d", id)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	pool.Close()
+	fmt.Printf("pending %d\n", journal.Pending())
+	return nil
+}
+
+// runChild starts this program as a worker process and collects the IDs it
+// reports as done. When killAfter > 0 the child is killed with SIGKILL.
+func runChild(journalPath string, submit bool, killAfter time.Duration, done map[string]int) error {
+	args := []string{"worker", journalPath}
+	if submit {
+		args = append(args, "submit")
+	}
+	cmd := exec.Command(os.Args[0], args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if killAfter > 0 {
+		timer := time.AfterFunc(killAfter, func() {
+			fmt.Println("  💀 killing worker process")
+			cmd.Process.Kill()
+		})
+		defer timer.Stop()
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Printf("  child: %s\n", line)
+		if id, ok := strings.CutPrefix(line, "done "); ok {
+			done[id]++
+		}
+	}
+	cmd.Wait() // Exit status is expected to be non-zero after a kill
+	return nil
+}
+
+func main() {
+	if len(os.Args) >= 3 && os.Args[1] == "worker" {
+		if err := runWorker(os.Args[2], len(os.Args) > 3); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("=== Durable Worker Pool Example ===")
+	journalPath := filepath.Join(os.TempDir(), "workerpool-demo.journal")
+	os.Remove(journalPath)
+	defer os.Remove(journalPath)
+
+	done := make(map[string]int)
+
+	// Run 1: submit 10 jobs, then crash halfway through
+	fmt.Println("\nRun 1: submit jobs and get killed")
+	if err := runChild(journalPath, true, time.Second, done); err != nil {
+		fmt.Println("Failed to start worker:", err)
+		return
+	}
+
+	// Run 2: restart; unacknowledged jobs are replayed from the journal
+	fmt.Println("\nRun 2: restart and replay")
+	if err := runChild(journalPath, false, 0, done); err != nil {
+		fmt.Println("Failed to start worker:", err)
+		return
+	}
+
+	// Every job ran at least once; a job that was running during the kill
+	// may have run twice (at-least-once delivery)
+	fmt.Println("\nVerification:")
+	missing := 0
+	for id := 1; id <= numJobs; id++ {
+		count := done[fmt.Sprint(id)]
+		if count == 0 {
+			missing++
+		}
+		if count > 1 {
+			fmt.Printf("  job %d ran %d times (redelivered after crash)\n", id, count)
+		}
+	}
+	if missing == 0 {
+		fmt.Printf("✅ all %d jobs completed across the crash\n", numJobs)
+	} else {
+		fmt.Printf("❌ %d jobs were lost\n", missing)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// ErrWarehouseDown simulates a failing upstream step
+var ErrWarehouseDown = errors.New("warehouse service down")
+
+// step returns a DAG node that sums its inputs, adds n and simulates work
+func step(name string, n int) workerpool.DAGFunc[int] {
+	return func(ctx context.Context, inputs map[string]int) (int, error) {
+		total := n
+		for _, v := range inputs {
+			total += v
+		}
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+		fmt.Printf("  %-10s inputs=%v -> %d\n", name, inputs, total)
+		return total, nil
+	}
+}
+
+// failing returns a DAG node that always fails
+func failing(ctx context.Context, _ map[string]int) (int, error) {
+	time.Sleep(100 * time.Millisecond)
+	return 0, ErrWarehouseDown
+}
+
+// build creates the report DAG:
+//
+//	users ─┐
+//	       ├─ join ─ report
+//	orders ┘
+//	stock ── restock
+func build(stockFails bool) *workerpool.DAG[int] {
+	dag := workerpool.NewDAG[int]()
+	dag.Add("users", step("users", 1))
+	dag.Add("orders", step("orders", 10))
+	dag.Add("join", step("join", 100), "users", "orders")
+	dag.Add("report", step("report", 1000), "join")
+	if stockFails {
+		dag.Add("stock", failing)
+	} else {
+		dag.Add("stock", step("stock", 5))
+	}
+	dag.Add("restock", step("restock", 50), "stock")
+	return dag
+}
+
+// printResults shows the final state of every node
+func printResults(results map[string]workerpool.NodeResult[int], err error) {
+	for _, id := range []string{"users", "orders", "join", "report", "stock", "restock"} {
+		r := results[id]
+		if r.Err != nil {
+			fmt.Printf("  %-10s %-9s err=%v\n", id, r.Status, r.Err)
+			continue
+		}
+		fmt.Printf("  %-10s %-9s value=%d\n", id, r.Status, r.Value)
+	}
+	fmt.Println("  run error:", err)
+}
+
+func main() {
+	fmt.Println("=== DAG Worker Pool Example ===")
+	ctx := context.Background()
+
+	// Create a pool that runs DAG nodes
+	pool := workerpool.New(3, workerpool.DAGHandler[int]())
+	defer pool.Close()
+
+	// Scenario 1: everything succeeds, results flow downstream
+	fmt.Println("\n--- All nodes succeed ---")
+	results, err := build(false).Run(ctx, pool, workerpool.SkipDependents)
+	printResults(results, err)
+
+	// Scenario 2: stock fails, only restock is skipped
+	fmt.Println("\n--- stock fails, SkipDependents ---")
+	results, err = build(true).Run(ctx, pool, workerpool.SkipDependents)
+	printResults(results, err)
+
+	// Scenario 3: stock fails, the whole run is cancelled
+	fmt.Println("\n--- stock fails, CancelAll ---")
+	results, err = build(true).Run(ctx, pool, workerpool.CancelAll)
+	printResults(results, err)
+
+	// Cycles are rejected before anything runs
+	fmt.Println("\n--- Cycle detection ---")
+	cyclic := workerpool.NewDAG[int]()
+	cyclic.Add("a", step("a", 1), "c")
+	cyclic.Add("b", step("b", 1), "a")
+	cyclic.Add("c", step("c", 1), "b")
+	_, err = cyclic.Run(ctx, pool, workerpool.SkipDependents)
+	fmt.Println("  run error:", err)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool/pipeline"
+)
+
+// ErrBadRecord simulates an input line that cannot be parsed
+var ErrBadRecord = errors.New("bad record")
+
+// Order is a parsed input line
+type Order struct {
+	ID     int
+	Amount int
+}
+
+// EnrichedOrder is an order with data looked up from another service
+type EnrichedOrder struct {
+	Order
+	Customer string
+}
+
+// parse turns "id,amount" into an Order
+func parse(ctx context.Context, line string) (Order, error) {
+	id, amount, ok := strings.Cut(line, ",")
+	if !ok {
+		return Order{}, fmt.Errorf("%w: %q", ErrBadRecord, line)
+	}
+	o := Order{}
+	o.ID, _ = strconv.Atoi(id)
+	o.Amount, _ = strconv.Atoi(amount)
+	return o, nil
+}
+
+// enrich simulates a slow lookup, so it gets the most workers
+func enrich(ctx context.Context, o Order) (EnrichedOrder, error) {
+	select {
+	case <-time.After(100 * time.Millisecond):
+		return EnrichedOrder{Order: o, Customer: fmt.Sprintf("customer-%d", o.ID%3)}, nil
+	case <-ctx.Done():
+		return EnrichedOrder{}, ctx.Err()
+	}
+}
+
+// run pushes lines through parse -> enrich -> write and returns what was written
+func run(lines []string) ([]EnrichedOrder, error) {
+	p := pipeline.New(context.Background())
+
+	// Each stage has its own concurrency and buffer
+	parsed := pipeline.Stage(p, "parse", pipeline.Source(p, slices.Values(lines)), 1, 4, parse)
+	enriched := pipeline.Stage(p, "enrich", parsed, 4, 8, enrich)
+
+	var mu sync.Mutex
+	var written []EnrichedOrder
+	pipeline.Sink(p, "write", enriched, 2, func(ctx context.Context, o EnrichedOrder) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, o)
+		return nil
+	})
+
+	err := p.Wait()
+	return written, err
+}
+
+func main() {
+	fmt.Println("=== Pipeline Example ===")
+	before := runtime.NumGoroutine()
+
+	// Scenario 1: all records are valid
+	fmt.Println("\n--- All records valid ---")
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("%d,%d", i, i*100))
+	}
+	start := time.Now()
+	written, err := run(lines)
+	fmt.Printf("Wrote %d orders in %v, err=%v\n", len(written), time.Since(start).Round(10*time.Millisecond), err)
+
+	// Scenario 2: one bad record cancels every stage
+	fmt.Println("\n--- One bad record ---")
+	lines[5] = "garbage"
+	written, err = run(lines)
+	fmt.Printf("Wrote %d orders before cancelling, err=%v\n", len(written), err)
+	fmt.Println("Is ErrBadRecord:", errors.Is(err, ErrBadRecord))
+
+	// Every stage goroutine and pool worker has exited
+	time.Sleep(50 * time.Millisecond)
+	fmt.Printf("\nGoroutines before: %d, after: %d\n", before, runtime.NumGoroutine())
+}
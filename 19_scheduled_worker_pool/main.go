@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// start is a Monday morning, so the weekday schedule below matches
+var start = time.Date(2026, time.January, 5, 8, 50, 0, 0, time.UTC)
+
+// newPool creates a pool that runs on the given clock
+func newPool(clock workerpool.Clock) *workerpool.Pool[string, string] {
+	return workerpool.New(2, func(ctx context.Context, job string) (string, error) {
+		return "done: " + job, nil
+	}, workerpool.WithClock(clock))
+}
+
+// printFire shows which job was submitted and when it was due
+func printFire(f workerpool.Fire[string, string]) {
+	if f.Err != nil {
+		fmt.Printf("  submit %-8s failed: %v\n", f.Job, f.Err)
+		return
+	}
+	fmt.Printf("  run %-8s due %s\n", f.Job, f.Scheduled.Format("Mon 15:04"))
+}
+
+// dailySchedule shows delayed and cron jobs driven by a fake clock
+func dailySchedule() {
+	fmt.Println("\n--- Delayed and cron jobs (fake clock, no real sleeping) ---")
+	clock := workerpool.NewFakeClock(start)
+	pool := newPool(clock)
+	defer pool.Close()
+
+	sched := workerpool.NewScheduler(pool, printFire)
+	defer sched.Stop()
+
+	// Every 15 minutes during office hours on weekdays, plus a one-off
+	sched.Cron(workerpool.CronJob[string]{Spec: "*/15 9-17 * * MON-FRI", Job: "sync"})
+	sched.After(25*time.Minute, "reminder")
+	sched.Start()
+
+	for range 9 {
+		// Wait until the scheduler is asleep, then move time forward
+		clock.BlockUntil(1)
+		now := clock.Now().Add(5 * time.Minute)
+		fmt.Println("clock:", now.Format("Mon 15:04"))
+		clock.Set(now)
+	}
+	clock.BlockUntil(1)
+
+	for _, e := range sched.Entries() {
+		fmt.Printf("next %q at %s\n", e.Spec, e.Next.Format("Mon 15:04"))
+	}
+}
+
+// downtime shows what each missed-run policy does when the process was
+// down for three hours and an hourly job missed its runs
+func downtime(policy workerpool.MissedRunPolicy) {
+	fmt.Printf("\n--- Restart after 3h downtime, policy %s ---\n", policy)
+	clock := workerpool.NewFakeClock(start.Add(4 * time.Hour)) // 12:50
+	pool := newPool(clock)
+	defer pool.Close()
+
+	sched := workerpool.NewScheduler(pool, printFire)
+	defer sched.Stop()
+
+	// LastRun would normally be loaded from storage
+	sched.Cron(workerpool.CronJob[string]{
+		Spec:    "@hourly",
+		Job:     "report",
+		Missed:  policy,
+		LastRun: start.Add(10 * time.Minute), // 09:00
+	})
+	sched.Start()
+	clock.BlockUntil(1)
+
+	// The 13:00 run is on time again
+	fmt.Println("clock: 13:00")
+	clock.Advance(10 * time.Minute)
+	clock.BlockUntil(1)
+}
+
+func main() {
+	fmt.Println("=== Scheduled Worker Pool Example ===")
+	dailySchedule()
+	downtime(workerpool.MissedSkip)
+	downtime(workerpool.MissedRunOnce)
+	downtime(workerpool.MissedRunAll)
+
+	// Cron expressions are validated up front
+	if _, err := workerpool.ParseCron("61 * * * *"); err != nil {
+		fmt.Println("\nInvalid spec:", err)
+	}
+}
@@ -1,39 +1,341 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"sync"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
 )
 
-// RunWorkers creates a worker pool to process jobs concurrently
-func RunWorkers(numWorkers, numJobs int) {
-	// Create channels for jobs and done signal
-	jobs := make(chan int, numJobs)
-	var wg sync.WaitGroup
+// RunWorkers creates a worker pool to process jobs concurrently. Cancelling
+// ctx stops dispatching; in-flight jobs get a short drain period to finish.
+func RunWorkers(ctx context.Context, numWorkers, numJobs int) workerpool.Summary {
+	// Create a pool whose handler prints and simulates work
+	pool := workerpool.New(numWorkers, func(ctx context.Context, jobID int) (struct{}, error) {
+		fmt.Printf("Worker %d processing job %d\n", workerpool.WorkerID(ctx), jobID)
+		err := workerpool.Sleep(ctx, 1*time.Second) // Simulate work on the pool's clock
+		return struct{}{}, err
+	},
+		workerpool.WithQueueSize(2*numWorkers), // Bounded: Submit blocks when workers fall behind
+		workerpool.WithContext(ctx),
+		workerpool.WithDrainTimeout(2*time.Second),
+	)
+
+	// Send jobs to the pool
+	for j := 1; j <= numJobs; j++ {
+		if _, err := pool.Submit(ctx, j); err != nil {
+			fmt.Printf("Stopped submitting at job %d: %v\n", j, err)
+			break
+		}
+	}
+
+	// Wait for all workers to finish (or for the drain after a signal)
+	return pool.Close()
+}
+
+// Job is one NDJSON input record. ID is taken from the record's "id" field
+// when present, otherwise it is the line number.
+type Job struct {
+	ID      string
+	Line    int
+	Payload json.RawMessage
+}
+
+// JobResult is one NDJSON output record
+type JobResult struct {
+	ID         string          `json:"id"`
+	Line       int             `json:"line"`
+	Status     string          `json:"status"` // ok, error, timeout, cancelled or invalid
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMS float64         `json:"duration_ms"`
+}
+
+// CLIConfig holds the flags of the NDJSON runner
+type CLIConfig struct {
+	Workers  int
+	Queue    int
+	Timeout  time.Duration
+	Retries  int
+	Ordered  bool
+	Handler  string // name in handlers
+	Command  string // shell command for the "exec" handler
+	Progress bool   // draw a progress line on stderr
+}
+
+// handlers are the job handlers selectable with -handler
+var handlers = map[string]func(cfg CLIConfig) (workerpool.Handler[Job, json.RawMessage], error){
+	"echo": func(CLIConfig) (workerpool.Handler[Job, json.RawMessage], error) {
+		return echoHandler, nil
+	},
+	"exec": func(cfg CLIConfig) (workerpool.Handler[Job, json.RawMessage], error) {
+		if cfg.Command == "" {
+			return nil, errors.New("the exec handler needs -cmd")
+		}
+		return execHandler(cfg.Command), nil
+	},
+}
+
+// echoHandler returns the job's payload unchanged
+func echoHandler(ctx context.Context, job Job) (json.RawMessage, error) {
+	return job.Payload, nil
+}
 
-	// Start workers
-	for w := range numWorkers {
-		wg.Go(func() {
-			for jobID := range jobs {
-				fmt.Printf("Worker %d processing job %d\n", w, jobID)
-				time.Sleep(1 * time.Second) // Simulate work
+// execHandler runs command with sh for every job. The job's JSON is written
+// to the command's stdin and JOB_ID is set in its environment; its stdout is
+// the result (embedded as JSON when it is valid JSON, else as a string).
+func execHandler(command string) workerpool.Handler[Job, json.RawMessage] {
+	return func(ctx context.Context, job Job) (json.RawMessage, error) {
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(job.Payload)
+		cmd.Env = append(os.Environ(), "JOB_ID="+job.ID)
+		cmd.WaitDelay = time.Second // Don't wait for children of sh that keep stdout open
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("%w: %s", err, msg)
 			}
-		})
+			return nil, err
+		}
+
+		out := bytes.TrimSpace(stdout.Bytes())
+		if json.Valid(out) {
+			return out, nil
+		}
+		return json.Marshal(string(out))
 	}
+}
 
-	// Send jobs to channel
-	for j := 1; j <= numJobs; j++ {
-		jobs <- j
+// readJobs parses NDJSON records from r and sends them to the returned
+// channel. Lines that are not valid JSON are reported on invalid.
+func readJobs(ctx context.Context, r io.Reader) (<-chan Job, <-chan JobResult, <-chan error) {
+	jobs := make(chan Job)
+	invalid := make(chan JobResult)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(jobs)
+		defer close(invalid)
+		defer close(errc)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+
+			job := Job{ID: strconv.Itoa(line), Line: line, Payload: json.RawMessage(bytes.Clone(text))}
+			var record struct {
+				ID any `json:"id"`
+			}
+			if err := json.Unmarshal(text, &record); err != nil {
+				select {
+				case invalid <- JobResult{ID: job.ID, Line: line, Status: "invalid", Error: err.Error()}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			if record.ID != nil {
+				job.ID = fmt.Sprint(record.ID)
+			}
+
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+
+	return jobs, invalid, errc
+}
+
+// toResult converts a pool result into an output record
+func toResult(r workerpool.Result[Job, json.RawMessage]) JobResult {
+	out := JobResult{
+		ID:         r.Job.ID,
+		Line:       r.Job.Line,
+		Status:     "ok",
+		Result:     r.Value,
+		DurationMS: float64(r.Duration.Microseconds()) / 1000,
+	}
+	switch {
+	case r.Err == nil:
+		return out
+	case errors.Is(r.Err, context.DeadlineExceeded):
+		out.Status = "timeout"
+	case errors.Is(r.Err, context.Canceled), errors.Is(r.Err, workerpool.ErrNotStarted), errors.Is(r.Err, workerpool.ErrPoolClosed):
+		out.Status = "cancelled"
+	default:
+		out.Status = "error"
+	}
+	out.Result = nil
+	out.Error = r.Err.Error()
+	return out
+}
+
+// RunCLI reads NDJSON jobs from in, runs them through the pool and writes
+// one NDJSON result per job to out. It returns the number of jobs that did
+// not succeed.
+func RunCLI(ctx context.Context, cfg CLIConfig, in io.Reader, out io.Writer) (int, error) {
+	newHandler, ok := handlers[cfg.Handler]
+	if !ok {
+		return 0, fmt.Errorf("unknown handler %q", cfg.Handler)
 	}
-	close(jobs) // Close channel when all jobs are sent
+	handler, err := newHandler(cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := []workerpool.Option{
+		workerpool.WithQueueSize(cfg.Queue),
+		workerpool.WithContext(ctx),
+		workerpool.WithDrainTimeout(2 * time.Second),
+	}
+	if cfg.Progress {
+		opts = append(opts, workerpool.WithProgress(250*time.Millisecond, workerpool.ProgressBar(os.Stderr)))
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, workerpool.WithJobTimeout(cfg.Timeout))
+	}
+	if cfg.Retries > 0 {
+		opts = append(opts, workerpool.WithRetry(workerpool.RetryPolicy{
+			MaxAttempts:    cfg.Retries + 1,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+			Jitter:         0.2,
+		}))
+	}
+	pool := workerpool.New(cfg.Workers, handler, opts...)
+	defer pool.Close()
+
+	// Results keep flowing after a signal so cancelled jobs are reported too
+	jobs, invalid, readErr := readJobs(ctx, in)
+	var results <-chan workerpool.Result[Job, json.RawMessage]
+	if cfg.Ordered {
+		results = pool.Ordered(context.WithoutCancel(ctx), jobs, 2*cfg.Workers)
+	} else {
+		results = pool.Unordered(context.WithoutCancel(ctx), jobs)
+	}
+
+	enc := json.NewEncoder(out)
+	failed := 0
+	for results != nil || invalid != nil {
+		var r JobResult
+		select {
+		case res, ok := <-results:
+			if !ok {
+				results = nil
+				continue
+			}
+			r = toResult(res)
+		case bad, ok := <-invalid:
+			if !ok {
+				invalid = nil
+				continue
+			}
+			r = bad
+		}
 
-	// Wait for all workers to finish
-	wg.Wait()
-	fmt.Println("All jobs completed!")
+		if r.Status != "ok" {
+			failed++
+		}
+		if err := enc.Encode(r); err != nil {
+			return failed, err
+		}
+	}
+	return failed, <-readErr
 }
 
 func main() {
+	var cfg CLIConfig
+	input := flag.String("input", "", `NDJSON job file, or "-" for stdin (runs the demo when empty)`)
+	numJobs := flag.Int("jobs", 10, "number of demo jobs")
+	flag.IntVar(&cfg.Workers, "workers", 3, "number of workers")
+	flag.IntVar(&cfg.Queue, "queue", 0, "queue size (default 2x workers)")
+	flag.DurationVar(&cfg.Timeout, "timeout", 0, "per-job timeout, e.g. 5s (0 for none)")
+	flag.IntVar(&cfg.Retries, "retries", 0, "retries for a failed job")
+	flag.BoolVar(&cfg.Ordered, "ordered", false, "write results in input order")
+	flag.StringVar(&cfg.Handler, "handler", "", "job handler: echo or exec (default exec with -cmd, else echo)")
+	flag.StringVar(&cfg.Command, "cmd", "", "shell command run per job by the exec handler; job JSON on stdin, JOB_ID in env")
+	flag.BoolVar(&cfg.Progress, "progress", false, "show done/total, throughput, failures and ETA on stderr")
+	flag.Parse()
+
+	if cfg.Workers < 1 {
+		fmt.Fprintln(os.Stderr, "-workers must be at least 1")
+		os.Exit(2)
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = 2 * cfg.Workers
+	}
+	if cfg.Handler == "" {
+		cfg.Handler = "echo"
+		if cfg.Command != "" {
+			cfg.Handler = "exec"
+		}
+	}
+
+	// Ctrl+C or SIGTERM cancels ctx and triggers a graceful drain
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *input != "" {
+		in := os.Stdin
+		if *input != "-" {
+			f, err := os.Open(*input)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		failed, err := RunCLI(ctx, cfg, in, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if failed > 0 {
+			stop()
+			os.Exit(3)
+		}
+		return
+	}
+
 	fmt.Println("=== Worker Pool Example ===")
-	RunWorkers(3, 10) // 3 workers, 10 jobs
+	fmt.Println("Press Ctrl+C to stop early")
+	summary := RunWorkers(ctx, cfg.Workers, *numJobs)
+
+	if summary.Cancelled == 0 && summary.NotStarted == 0 {
+		fmt.Println("All jobs completed!")
+		return
+	}
+	fmt.Printf("Stopped early: %d completed, %d cancelled, %d never started\n",
+		summary.Completed, summary.Cancelled, summary.NotStarted)
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// The benchmarks live in workerpool/stealing_test.go. This example only runs
+// them, the same as:
+//
+//	go test -run '^$' -bench . ./workerpool
+func main() {
+	fmt.Println("=== Work-Stealing Benchmark ===")
+	fmt.Printf("%d workers, one producer; p50/p99/max are submit -> handler done\n", runtime.GOMAXPROCS(0))
+	fmt.Println("Channel is the original RunWorkers design, SharedQueue the pool's default queue")
+	fmt.Println("and WorkStealing the per-worker deques; short, spiky and long are the job mixes.")
+	fmt.Println()
+
+	cmd := exec.Command("go", "test", "-run", "^$", "-bench", ".", "github.com/NatthawutSkc2015/go-programming/workerpool")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "benchmark failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("\nThe pool designs also create a Future per job, which the channel design does not.")
+}
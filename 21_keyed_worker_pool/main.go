@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Transfer changes one account's balance
+type Transfer struct {
+	Account string
+	Seq     int // order in which transfers for the account were submitted
+	Amount  int
+}
+
+// ledger holds balances without any per-account locking: the pool's key
+// affinity guarantees one transfer per account at a time
+type ledger struct {
+	mu       sync.Mutex // guards the maps themselves, not the read-modify-write
+	balances map[string]int
+	history  map[string][]int
+}
+
+func (l *ledger) get(account string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.balances[account]
+}
+
+func (l *ledger) set(account string, balance, seq int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.balances[account] = balance
+	l.history[account] = append(l.history[account], seq)
+}
+
+func main() {
+	fmt.Println("=== Keyed Worker Pool Example ===")
+	l := &ledger{balances: make(map[string]int), history: make(map[string][]int)}
+
+	// A read-modify-write with a pause in the middle: without key affinity
+	// two transfers for the same account would overwrite each other
+	pool := workerpool.New(4, func(ctx context.Context, t Transfer) (int, error) {
+		balance := l.get(t.Account)
+		time.Sleep(20 * time.Millisecond)
+		l.set(t.Account, balance+t.Amount, t.Seq)
+		return balance + t.Amount, nil
+	}, workerpool.WithQueueSize(100))
+
+	// "hot" gets 30 transfers, the others 3 each, all interleaved
+	start := time.Now()
+	done := make(map[string]time.Duration)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seq := make(map[string]int)
+	for i := range 45 {
+		account := "hot"
+		if i%3 == 2 {
+			account = fmt.Sprintf("acct-%d", i%5)
+		}
+		seq[account]++
+		t := Transfer{Account: account, Seq: seq[account], Amount: 10}
+
+		future, err := pool.Submit(context.Background(), t, workerpool.WithKey(account))
+		if err != nil {
+			fmt.Println("submit failed:", err)
+			continue
+		}
+		wg.Go(func() {
+			future.Wait()
+			mu.Lock()
+			done[account] = time.Since(start)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	pool.Close()
+
+	// Every account has the full balance and saw its transfers in order
+	for _, account := range []string{"acct-0", "acct-1", "acct-2", "acct-3", "acct-4", "hot"} {
+		fmt.Printf("%-7s balance=%3d transfers=%2d in order=%v last done after %v\n",
+			account, l.balances[account], seq[account], slices.IsSorted(l.history[account]),
+			done[account].Round(10*time.Millisecond))
+	}
+	fmt.Println("\nThe hot account runs serially (30 x 20ms), but does not hold up the others.")
+}
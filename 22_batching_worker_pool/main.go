@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// ErrInvalidEmail simulates a row the database rejects
+var ErrInvalidEmail = errors.New("invalid email")
+
+// User is a row to insert
+type User struct {
+	ID    int
+	Email string
+}
+
+// roundTrips counts calls to the simulated database
+var roundTrips atomic.Int64
+
+// bulkInsert simulates a database call that costs the same for 1 row or
+// 50, and rejects individual bad rows without failing the rest
+func bulkInsert(ctx context.Context, users []User) ([]int, error) {
+	roundTrips.Add(1)
+	time.Sleep(20 * time.Millisecond)
+
+	ids := make([]int, len(users))
+	failed := workerpool.BatchError{}
+	for i, u := range users {
+		if u.Email == "" {
+			failed[i] = fmt.Errorf("user %d: %w", u.ID, ErrInvalidEmail)
+			continue
+		}
+		ids[i] = 1000 + u.ID
+	}
+	if len(failed) > 0 {
+		return ids, failed
+	}
+	return ids, nil
+}
+
+// insertOne wraps bulkInsert for the one-job-at-a-time pool
+func insertOne(ctx context.Context, u User) (int, error) {
+	ids, err := bulkInsert(ctx, []User{u})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// users returns n users; every 25th has no email
+func users(n int) []User {
+	list := make([]User, n)
+	for i := range list {
+		list[i] = User{ID: i + 1, Email: fmt.Sprintf("user%d@example.com", i+1)}
+		if (i+1)%25 == 0 {
+			list[i].Email = ""
+		}
+	}
+	return list
+}
+
+func main() {
+	fmt.Println("=== Micro-Batching Worker Pool Example ===")
+	ctx := context.Background()
+
+	// One row per call
+	roundTrips.Store(0)
+	start := time.Now()
+	pool := workerpool.New(4, insertOne, workerpool.WithQueueSize(200))
+	for _, u := range users(200) {
+		pool.Submit(ctx, u)
+	}
+	pool.Close()
+	fmt.Printf("\nOne by one: 200 rows, %d round trips, %v\n",
+		roundTrips.Load(), time.Since(start).Round(10*time.Millisecond))
+
+	// Up to 50 rows per call, or whatever arrived within 10ms
+	roundTrips.Store(0)
+	start = time.Now()
+	batcher := workerpool.NewBatcher(4, bulkInsert, 50, 10*time.Millisecond)
+	var futures []*workerpool.Future[int]
+	for _, u := range users(200) {
+		f, _ := batcher.Submit(ctx, u)
+		futures = append(futures, f)
+	}
+
+	// A slow trickle never fills a batch; max wait sends it anyway
+	for _, u := range []User{{ID: 201, Email: "late@example.com"}, {ID: 202, Email: "later@example.com"}} {
+		time.Sleep(15 * time.Millisecond)
+		f, _ := batcher.Submit(ctx, u)
+		futures = append(futures, f)
+	}
+
+	// Each job gets its own result, even when its batch partly failed
+	failed := 0
+	for i, f := range futures {
+		id, err := f.Wait()
+		if errors.Is(err, ErrInvalidEmail) {
+			failed++
+			continue
+		}
+		if i%50 == 0 || i >= 200 {
+			fmt.Printf("  user %d -> id %d\n", i+1, id)
+		}
+	}
+	batcher.Close()
+
+	s := batcher.Stats()
+	fmt.Printf("Batched: %d rows, %d round trips, %v, %d rejected rows\n",
+		s.Jobs, roundTrips.Load(), time.Since(start).Round(10*time.Millisecond), failed)
+	fmt.Printf("Batches: %d full, %d by max wait, %d flushed\n", s.Full, s.Timeout, s.Flushed)
+}
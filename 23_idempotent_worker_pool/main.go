@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Payment is a charge that must not happen twice
+type Payment struct {
+	OrderID string
+	Amount  int
+}
+
+// charges counts calls to the simulated payment provider
+var charges atomic.Int64
+
+// charge simulates a slow call to a payment provider
+func charge(ctx context.Context, p Payment) (string, error) {
+	n := charges.Add(1)
+	time.Sleep(100 * time.Millisecond)
+	return fmt.Sprintf("receipt-%d (%s, %d THB)", n, p.OrderID, p.Amount), nil
+}
+
+// pay submits a payment keyed by its order, as a client retrying a request would
+func pay(pool *workerpool.Pool[Payment, string], p Payment) string {
+	future, err := pool.Submit(context.Background(), p, workerpool.WithIdempotencyKey(p.OrderID))
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	receipt, err := future.Wait()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return receipt
+}
+
+func main() {
+	fmt.Println("=== Idempotent Worker Pool Example ===")
+
+	// Successful results are remembered for 300ms, at most 1000 of them
+	pool := workerpool.New(4, charge, workerpool.WithDedup(300*time.Millisecond, 1000))
+	defer pool.Close()
+
+	// A user double-clicks "pay": five concurrent requests for one order
+	fmt.Println("\n--- 5 concurrent submits while the first is running ---")
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Go(func() {
+			fmt.Printf("  request %d: %s\n", i+1, pay(pool, Payment{OrderID: "order-1", Amount: 500}))
+		})
+	}
+	wg.Wait()
+
+	// The client retries after it already got an answer
+	fmt.Println("\n--- Retry within the TTL gets the cached result ---")
+	fmt.Println("  retry:", pay(pool, Payment{OrderID: "order-1", Amount: 500}))
+
+	// Another order is a different key and runs normally
+	fmt.Println("  other order:", pay(pool, Payment{OrderID: "order-2", Amount: 90}))
+
+	// Once the TTL has passed the key is forgotten
+	fmt.Println("\n--- After the TTL the job runs again ---")
+	time.Sleep(400 * time.Millisecond)
+	fmt.Println("  late retry:", pay(pool, Payment{OrderID: "order-1", Amount: 500}))
+
+	s := pool.Stats()
+	fmt.Printf("\nProvider called %d times for %d submits (%d deduplicated)\n",
+		charges.Load(), s.Submitted+s.Deduplicated, s.Deduplicated)
+}
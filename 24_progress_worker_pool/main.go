@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// resize simulates an image job that sometimes fails
+func resize(ctx context.Context, id int) (string, error) {
+	time.Sleep(time.Duration(10+rand.IntN(40)) * time.Millisecond)
+	if id%25 == 0 {
+		return "", errors.New("corrupt image")
+	}
+	return fmt.Sprintf("image-%d.jpg", id), nil
+}
+
+func main() {
+	fmt.Println("=== Progress Worker Pool Example ===")
+	const numJobs = 300
+
+	// A terminal progress bar, redrawn every 200ms on stderr
+	fmt.Println("\n--- Progress bar ---")
+	pool := workerpool.New(8, resize,
+		workerpool.WithProgress(200*time.Millisecond, workerpool.ProgressBar(os.Stderr)),
+	)
+	pool.SetTotal(numJobs) // Known up front, so the ETA is right from the start
+	for id := 1; id <= numJobs; id++ {
+		pool.Submit(context.Background(), id)
+	}
+	pool.Close()
+
+	// The same snapshots can feed logs or any other UI
+	fmt.Println("\n--- Progress events as log lines ---")
+	pool = workerpool.New(8, resize,
+		workerpool.WithProgress(500*time.Millisecond, func(p workerpool.Progress) {
+			fmt.Printf("  %s %5.1f%% done=%d failed=%d rate=%.0f/s eta=%v\n",
+				time.Now().Format("15:04:05.000"), p.Percent(), p.Done, p.Failed, p.Rate, p.ETA.Round(time.Millisecond))
+		}),
+	)
+	pool.SetTotal(numJobs)
+	for id := 1; id <= numJobs; id++ {
+		pool.Submit(context.Background(), id)
+	}
+	pool.Close()
+
+	// Or poll a snapshot whenever it is needed
+	fmt.Printf("\nFinal: %s\n", pool.Progress())
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+	"github.com/NatthawutSkc2015/go-programming/workerpool/remote"
+)
+
+// square is the job handler the agents run
+func square(ctx context.Context, n int) (string, error) {
+	select {
+	case <-time.After(300 * time.Millisecond): // Simulate work
+		return fmt.Sprintf("%d² = %d (agent pid %d)", n, n*n, os.Getpid()), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// runAgent works for the coordinator at url until interrupted. With
+// crashAfter > 0 the process dies abruptly once it has taken that many jobs.
+func runAgent(url string, workers, crashAfter int) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var taken atomic.Int64
+	agent := remote.NewAgent(url, workers, func(ctx context.Context, n int) (string, error) {
+		if crashAfter > 0 && taken.Add(1) > int64(crashAfter) {
+			fmt.Printf("  [agent %d] crashing while holding job %d\n", os.Getpid(), n)
+			os.Exit(1)
+		}
+		return square(ctx, n)
+	})
+	agent.Run(ctx)
+}
+
+// startAgent runs this program again as an agent process
+func startAgent(url string, crashAfter int) *exec.Cmd {
+	self, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+	cmd := exec.Command(self, "-agent", url, "-workers", "2", "-crash-after", fmt.Sprint(crashAfter))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func main() {
+	agentURL := flag.String("agent", "", "run as an agent of the coordinator at this URL")
+	workers := flag.Int("workers", 2, "jobs an agent runs at once")
+	crashAfter := flag.Int("crash-after", 0, "agent only: exit abruptly after taking this many jobs (0 never)")
+	flag.Parse()
+
+	if *agentURL != "" {
+		runAgent(*agentURL, *workers, *crashAfter)
+		return
+	}
+
+	fmt.Println("=== Distributed Worker Pool Example ===")
+
+	// The coordinator owns the queue and serves it on a local port
+	coord := remote.NewCoordinator[int, string](remote.Config{
+		Capacity:    8,
+		LeaseTTL:    time.Second, // A silent agent loses its jobs after 1s
+		PollTimeout: 5 * time.Second,
+	}, workerpool.WithQueueSize(100))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	go http.Serve(listener, coord)
+	url := "http://" + listener.Addr().String()
+	fmt.Println("Coordinator listening on", url)
+
+	// Two agent processes; the second one crashes on its third job
+	agents := []*exec.Cmd{startAgent(url, 0), startAgent(url, 2)}
+	fmt.Printf("Started agents with pid %d and %d (the second will crash)\n\n",
+		agents[0].Process.Pid, agents[1].Process.Pid)
+
+	var futures []*workerpool.Future[string]
+	for n := 1; n <= 12; n++ {
+		future, err := coord.Submit(context.Background(), n)
+		if err != nil {
+			panic(err)
+		}
+		futures = append(futures, future)
+	}
+
+	// Jobs held by the crashed agent come back once their lease expires
+	for _, future := range futures {
+		result, err := future.Wait()
+		if err != nil {
+			fmt.Println("  error:", err)
+			continue
+		}
+		fmt.Println(" ", result)
+	}
+
+	summary := coord.Close()
+	stats := coord.Stats()
+	fmt.Printf("\nCompleted %d jobs: %d leases granted, %d expired and re-queued\n",
+		summary.Completed, stats.Leased, stats.Expired)
+
+	// Stop the remaining agent
+	for _, cmd := range agents {
+		cmd.Process.Signal(os.Interrupt)
+		cmd.Wait()
+	}
+}
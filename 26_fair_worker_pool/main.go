@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Job is a unit of work from one team
+type Job struct {
+	Team string
+	ID   int
+}
+
+// process simulates a job that takes 10ms
+func process(ctx context.Context, job Job) (string, error) {
+	time.Sleep(10 * time.Millisecond)
+	return fmt.Sprintf("%s-%d", job.Team, job.ID), nil
+}
+
+// run floods the pool with 200 "reports" jobs, then submits 10 "alerts"
+// jobs and reports how long the alerts team waited for all of its results
+func run(name string, opts ...workerpool.Option) {
+	pool := workerpool.New(4, process, append(opts, workerpool.WithQueueSize(1000))...)
+
+	for id := 1; id <= 200; id++ {
+		pool.Submit(context.Background(), Job{Team: "reports", ID: id}, workerpool.WithTenant("reports"))
+	}
+
+	start := time.Now()
+	var alerts []*workerpool.Future[string]
+	for id := 1; id <= 10; id++ {
+		future, _ := pool.Submit(context.Background(), Job{Team: "alerts", ID: id}, workerpool.WithTenant("alerts"))
+		alerts = append(alerts, future)
+	}
+	for _, future := range alerts {
+		future.Wait()
+	}
+	fmt.Printf("%-24s alerts done after %v\n", name, time.Since(start).Round(time.Millisecond))
+
+	// Per-tenant queue depth and wait time, only with WithTenants
+	if tenants := pool.Tenants(); tenants != nil {
+		for _, name := range slices.Sorted(maps.Keys(tenants)) {
+			t := tenants[name]
+			fmt.Printf("  %-8s weight=%d queued=%3d dispatched=%3d avg wait=%v\n",
+				name, t.Weight, t.Queued, t.Dispatched, t.AvgWait().Round(time.Millisecond))
+		}
+	}
+	pool.Close()
+}
+
+func main() {
+	fmt.Println("=== Fair Queuing Worker Pool Example ===")
+	fmt.Println("4 workers, 200 report jobs queued before 10 alert jobs (10ms each)")
+	fmt.Println()
+
+	// One FIFO queue: the alerts wait behind every report
+	run("Single FIFO queue:")
+
+	// Deficit round-robin: alerts get 3 jobs for every report job
+	run("Fair queuing (3:1):", workerpool.WithTenants(map[string]int{"alerts": 3, "reports": 1}))
+}
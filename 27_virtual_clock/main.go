@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// start is where the fake clock begins
+var start = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// step waits until n timers are armed, i.e. the pool is asleep, then moves
+// the fake clock forward by d. Timers and callbacks that are due have fired
+// by the time it returns.
+func step(clock *workerpool.FakeClock, n int, d time.Duration) {
+	clock.BlockUntil(n)
+	clock.Advance(d)
+}
+
+func main() {
+	fmt.Println("=== Virtual Clock Example ===")
+
+	// 9 one-second jobs on 3 workers take 3s of fake time
+	fmt.Println("\n--- Sleeping handlers ---")
+	clock := workerpool.NewFakeClock(start)
+	pool := workerpool.New(3, func(ctx context.Context, id int) (string, error) {
+		// Sleeps on the pool's clock instead of calling time.Sleep
+		if err := workerpool.Sleep(ctx, time.Second); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("job %d", id), nil
+	}, workerpool.WithClock(clock), workerpool.WithQueueSize(10))
+
+	realStart := time.Now()
+	var futures []*workerpool.Future[string]
+	for id := 1; id <= 9; id++ {
+		future, _ := pool.Submit(context.Background(), id)
+		futures = append(futures, future)
+	}
+	// Each step finishes one wave of 3 sleeping jobs
+	for range 3 {
+		step(clock, 3, time.Second)
+	}
+	for _, future := range futures {
+		future.Wait()
+	}
+	pool.Close()
+	fmt.Printf("9 jobs done after %v of fake time (%v real)\n", clock.Now().Sub(start), time.Since(realStart).Round(time.Millisecond))
+
+	// Retry backoff of minutes and a per-job timeout, checked instantly
+	fmt.Println("\n--- Retries and timeouts ---")
+	clock = workerpool.NewFakeClock(start)
+	var attempts atomic.Int64
+	jobs := workerpool.New(2, func(ctx context.Context, job string) (string, error) {
+		switch job {
+		case "flaky":
+			if n := attempts.Add(1); n < 3 {
+				return "", fmt.Errorf("attempt %d failed", n)
+			}
+			return "flaky succeeded on attempt 3", nil
+		default:
+			if err := workerpool.Sleep(ctx, time.Hour); err != nil {
+				return "", workerpool.Permanent(err)
+			}
+			return "slow finished", nil
+		}
+	},
+		workerpool.WithClock(clock),
+		workerpool.WithRetry(workerpool.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute}),
+	)
+
+	realStart = time.Now()
+	flaky, _ := jobs.Submit(context.Background(), "flaky")
+	slow, _ := jobs.Submit(context.Background(), "slow", workerpool.WithTimeout(30*time.Second))
+
+	// Armed: slow's timeout and its sleep, and flaky's first retry backoff
+	step(clock, 3, 30*time.Second)
+	_, err := slow.Wait()
+	fmt.Printf("slow:  %v after %v (deadline exceeded: %v)\n", err, clock.Now().Sub(start), errors.Is(err, context.DeadlineExceeded))
+
+	clock.Advance(30 * time.Second) // The 1m backoff is over
+	step(clock, 1, 2*time.Minute)   // and then the 2m one
+	result, _ := flaky.Wait()
+	fmt.Printf("flaky: %s after %v (backoff 1m + 2m)\n", result, clock.Now().Sub(start))
+	jobs.Close()
+	fmt.Printf("Both checked in %v of real time\n", time.Since(realStart).Round(time.Millisecond))
+}
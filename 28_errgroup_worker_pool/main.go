@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// errChecksum is what a corrupt file fails with
+var errChecksum = errors.New("checksum mismatch")
+
+// verify simulates checking one file; the corrupt ones fail
+func verify(corrupt map[int]bool, running *atomic.Int32) workerpool.Handler[int, string] {
+	return func(ctx context.Context, file int) (string, error) {
+		running.Add(1)
+		defer running.Add(-1)
+
+		// Reading the header can't be interrupted, so a corrupt file fails
+		// even when the group was cancelled meanwhile
+		time.Sleep(50 * time.Millisecond)
+		if corrupt[file] {
+			fmt.Printf("file %d: FAILED\n", file)
+			return "", fmt.Errorf("file %d: %w", file, errChecksum)
+		}
+		if err := workerpool.Sleep(ctx, time.Duration(100+rand.IntN(200))*time.Millisecond); err != nil {
+			fmt.Printf("file %d: cancelled\n", file)
+			return "", err
+		}
+		fmt.Printf("file %d: ok (%d running)\n", file, running.Load())
+		return fmt.Sprintf("file-%d.bin", file), nil
+	}
+}
+
+// RunGroup verifies numFiles files on numWorkers workers and stops at the
+// first corrupt one
+func RunGroup(numWorkers, numFiles int, corrupt map[int]bool) {
+	var running atomic.Int32
+	g := workerpool.NewGroup(context.Background(), numWorkers, verify(corrupt, &running))
+
+	// Go blocks while the workers and queue are full, so at most numWorkers
+	// files are checked at once
+	submitted := 0
+	for file := 1; file <= numFiles; file++ {
+		if _, err := g.Go(file); err != nil {
+			fmt.Printf("stopped submitting at file %d: %v\n", file, err)
+			break
+		}
+		submitted++
+	}
+
+	err := g.Wait()
+	stats := g.Pool().Stats()
+	fmt.Printf("submitted %d, started %d of %d files\n", submitted, stats.Completed+stats.Failed, numFiles)
+
+	var groupErr *workerpool.GroupError
+	switch {
+	case errors.As(err, &groupErr):
+		fmt.Printf("first error: %v\n", groupErr.First())
+		fmt.Printf("all errors:  %v\n", groupErr.Errs)
+		fmt.Printf("is checksum: %v\n", errors.Is(err, errChecksum))
+	case err != nil:
+		fmt.Printf("group cancelled: %v\n", err)
+	default:
+		fmt.Println("all files ok")
+	}
+}
+
+func main() {
+	fmt.Println("=== Error Group Example ===")
+
+	fmt.Println("\n--- All files ok ---")
+	RunGroup(3, 6, nil)
+
+	fmt.Println("\n--- File 8 is corrupt ---")
+	RunGroup(3, 20, map[int]bool{8: true})
+
+	// Both are already running when the first fails, so both are reported
+	fmt.Println("\n--- Files 2 and 3 are corrupt ---")
+	RunGroup(4, 20, map[int]bool{2: true, 3: true})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Job is a request to one downstream service
+type Job struct {
+	Service string
+	ID      int
+}
+
+// downstream simulates the services behind the handler: payments is down
+// for its first second, email always works
+type downstream struct {
+	start time.Time
+	calls map[string]*atomic.Int64
+}
+
+func (d *downstream) handle(ctx context.Context, job Job) (string, error) {
+	d.calls[job.Service].Add(1)
+	time.Sleep(20 * time.Millisecond)
+	if job.Service == "payments" && time.Since(d.start) < time.Second {
+		return "", errors.New("payments: 503 service unavailable")
+	}
+	return fmt.Sprintf("%s-%d ok", job.Service, job.ID), nil
+}
+
+func main() {
+	fmt.Println("=== Circuit Breaker Worker Pool Example ===")
+	fmt.Println("payments is down for 1s, email is healthy; one circuit per service")
+	fmt.Println()
+
+	d := &downstream{
+		start: time.Now(),
+		calls: map[string]*atomic.Int64{"payments": {}, "email": {}},
+	}
+	pool := workerpool.New(4, d.handle,
+		workerpool.WithQueueSize(100),
+		workerpool.WithBreaker(workerpool.Breaker{
+			Window:      5 * time.Second,
+			MinRequests: 5,
+			FailureRate: 0.5,
+			CoolDown:    500 * time.Millisecond,
+		}),
+		// Jobs rejected by an open circuit are retried after the cool-down
+		workerpool.WithRetry(workerpool.RetryPolicy{MaxAttempts: 6, InitialBackoff: 100 * time.Millisecond}),
+		workerpool.WithDeadLetters(100),
+		workerpool.WithEvents(func(e workerpool.Event) {
+			fmt.Printf("[%6v] %-17s %-8s %s\n", time.Since(d.start).Round(time.Millisecond), e.Type, e.Circuit, e.Reason)
+		}),
+	)
+
+	var futures []*workerpool.Future[string]
+	for id := 1; id <= 20; id++ {
+		for _, service := range []string{"payments", "email"} {
+			future, _ := pool.Submit(context.Background(), Job{Service: service, ID: id}, workerpool.WithCircuit(service))
+			futures = append(futures, future)
+		}
+	}
+
+	succeeded, rejected := 0, 0
+	for _, future := range futures {
+		_, err := future.Wait()
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, workerpool.ErrCircuitOpen):
+			rejected++
+		}
+	}
+	pool.Close()
+
+	fmt.Println()
+	fmt.Printf("jobs: %d succeeded, %d gave up while the circuit was open, %d dead letters\n",
+		succeeded, rejected, pool.DeadLetters().Len())
+	circuits := pool.Circuits()
+	for _, name := range slices.Sorted(maps.Keys(circuits)) {
+		c := circuits[name]
+		fmt.Printf("  %-8s state=%-9s handler calls=%2d rejected=%2d opened=%d\n",
+			name, c.State, d.calls[name].Load(), c.Rejected, c.Opened)
+	}
+}
@@ -1,51 +1,95 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"iter"
+	"math/rand/v2"
+	"runtime"
 	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
 )
 
-// RunWorkers creates a worker pool to process jobs concurrently
-func RunWorkers(numWorkers, numJobs int) {
-	// Create channels for jobs and results
-	jobs := make(chan int, numJobs)
-	results := make(chan int, numJobs)
-	var wg sync.WaitGroup
-
-	// Start workers
-	for range numWorkers {
-		wg.Go(func() {
-			for jobID := range jobs {
-				time.Sleep(1 * time.Second) // Simulate work
-				// Send result (jobID * 2) to results channel
-				results <- jobID * 2
-			}
-		})
-	}
+// JobResult is the value each job produces, tagged with its job ID
+type JobResult struct {
+	JobID int
+	Value int
+	Took  time.Duration
+}
 
-	// Start a goroutine to close results channel after all workers finish
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// double is the job handler: it returns jobID * 2 as the result
+func double(ctx context.Context, jobID int) (JobResult, error) {
+	// Simulate work that takes a different amount of time per job
+	took := time.Duration(500+rand.IntN(1000)) * time.Millisecond
+	if err := workerpool.Sleep(ctx, took); err != nil {
+		return JobResult{}, err
+	}
+	return JobResult{JobID: jobID, Value: jobID * 2, Took: took}, nil
+}
 
-	// Send jobs to channel
-	for j := 1; j <= numJobs; j++ {
-		jobs <- j
+// jobIDs yields job IDs 1..numJobs
+func jobIDs(numJobs int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for j := 1; j <= numJobs; j++ {
+			if !yield(j) {
+				return
+			}
+		}
 	}
-	close(jobs) // Close channel when all jobs are sent
+}
 
-	// Wait for and collect all results
+// RunWorkers processes jobs concurrently and prints results as soon as each
+// job finishes, tagged with its job ID
+func RunWorkers(numWorkers, numJobs int) {
+	// The range loop replaces the results channel and its closing goroutine
 	fmt.Println("Collecting results:")
-	for result := range results {
-		fmt.Printf("Result received: %d\n", result)
+	for result, err := range workerpool.Stream(context.Background(), numWorkers, jobIDs(numJobs), double) {
+		if err != nil {
+			fmt.Printf("Job failed: %v\n", err)
+			continue
+		}
+		fmt.Printf("Result received: job %d -> %d (took %v)\n", result.JobID, result.Value, result.Took)
 	}
 
 	fmt.Println("All jobs completed!")
 }
 
+// RunWorkersOrdered processes jobs concurrently but prints results in the
+// same order the jobs were submitted
+func RunWorkersOrdered(numWorkers, numJobs int) {
+	fmt.Println("Collecting results in submission order:")
+	for result, err := range workerpool.Map(context.Background(), numWorkers, jobIDs(numJobs), double) {
+		if err != nil {
+			fmt.Printf("Job failed: %v\n", err)
+			continue
+		}
+		fmt.Printf("Result received: job %d -> %d (took %v)\n", result.JobID, result.Value, result.Took)
+	}
+
+	fmt.Println("All jobs completed!")
+}
+
+// RunUntilFound stops at the first result above target. Breaking out of the
+// loop cancels the jobs still running and releases every goroutine.
+func RunUntilFound(numWorkers, numJobs, target int) {
+	before := runtime.NumGoroutine()
+	for result, err := range workerpool.Stream(context.Background(), numWorkers, jobIDs(numJobs), double) {
+		if err == nil && result.Value > target {
+			fmt.Printf("Found job %d -> %d, stopping\n", result.JobID, result.Value)
+			break
+		}
+	}
+	fmt.Printf("Goroutines before: %d, after: %d\n", before, runtime.NumGoroutine())
+}
+
 func main() {
 	fmt.Println("=== Worker Pool Example ===")
 	RunWorkers(3, 10) // 3 workers, 10 jobs
+
+	fmt.Println("\n=== Ordered Worker Pool Example ===")
+	RunWorkersOrdered(3, 10)
+
+	fmt.Println("\n=== Early Exit Example ===")
+	RunUntilFound(3, 1000, 10)
 }
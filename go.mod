@@ -0,0 +1,3 @@
+module github.com/NatthawutSkc2015/go-programming
+
+go 1.25
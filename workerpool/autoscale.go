@@ -0,0 +1,108 @@
+package workerpool
+
+import (
+	"fmt"
+	"time"
+)
+
+// Autoscale configures a pool whose worker count follows the load. The
+// numWorkers passed to New becomes the initial count, clamped to the bounds.
+type Autoscale struct {
+	MinWorkers int // lower bound, at least 1
+	MaxWorkers int // upper bound
+
+	// A worker is added when more than QueueThreshold jobs are waiting, or
+	// when the p95 job latency exceeds LatencyThreshold (0 disables it)
+	QueueThreshold   int
+	LatencyThreshold time.Duration
+
+	IdleTimeout time.Duration // a worker idle this long is retired (down to MinWorkers)
+	CoolDown    time.Duration // minimum time between two scale-ups
+	Interval    time.Duration // how often the load is checked
+}
+
+// withDefaults fills in zero fields with sensible values
+func (a Autoscale) withDefaults(numWorkers int) Autoscale {
+	a.MinWorkers = max(a.MinWorkers, 1)
+	a.MaxWorkers = max(a.MaxWorkers, a.MinWorkers, numWorkers)
+	if a.IdleTimeout <= 0 {
+		a.IdleTimeout = 10 * time.Second
+	}
+	if a.Interval <= 0 {
+		a.Interval = 100 * time.Millisecond
+	}
+	return a
+}
+
+// autoscale periodically compares the load with the thresholds and adds
+// workers while the pool is behind
+func (p *Pool[J, R]) autoscale() {
+	ticker := p.clock.NewTicker(p.scale.Interval)
+	defer ticker.Stop()
+
+	var lastScaleUp time.Time
+	for {
+		select {
+		case <-ticker.C():
+		case <-p.finished:
+			return
+		}
+
+		if p.clock.Now().Sub(lastScaleUp) < p.scale.CoolDown {
+			continue
+		}
+
+		var reason string
+		depth := p.queueLen()
+		p95 := p.latency.percentile(0.95)
+		switch {
+		case depth > p.scale.QueueThreshold:
+			reason = fmt.Sprintf("queue depth %d > %d", depth, p.scale.QueueThreshold)
+		case p.scale.LatencyThreshold > 0 && p95 > p.scale.LatencyThreshold:
+			reason = fmt.Sprintf("p95 latency %v > %v", p95, p.scale.LatencyThreshold)
+		default:
+			continue
+		}
+
+		if p.spawn(reason) {
+			lastScaleUp = p.clock.Now()
+		}
+	}
+}
+
+// spawn starts one more worker on behalf of the autoscaler. It refuses when
+// the pool is at MaxWorkers or all workers have already exited.
+func (p *Pool[J, R]) spawn(reason string) bool {
+	p.workersMu.Lock()
+	if p.workers == 0 || p.workers >= p.scale.MaxWorkers {
+		p.workersMu.Unlock()
+		return false
+	}
+	p.startWorkerLocked()
+	p.scaleUps++
+	workers := p.workers
+	p.workersMu.Unlock()
+
+	p.publish(Event{Type: EventScaleUp, Workers: workers, Reason: reason})
+	return true
+}
+
+// retire lets an idle worker exit unless the pool is already at MinWorkers
+func (p *Pool[J, R]) retire() bool {
+	p.workersMu.Lock()
+	if p.workers <= p.scale.MinWorkers {
+		p.workersMu.Unlock()
+		return false
+	}
+	p.workers--
+	p.scaleDowns++
+	workers := p.workers
+	p.workersMu.Unlock()
+
+	p.publish(Event{
+		Type:    EventScaleDown,
+		Workers: workers,
+		Reason:  fmt.Sprintf("idle for %v", p.scale.IdleTimeout),
+	})
+	return true
+}
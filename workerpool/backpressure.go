@@ -0,0 +1,105 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+)
+
+// OverflowPolicy decides what Submit does when the queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Submit wait until a worker frees a slot (default)
+	OverflowBlock OverflowPolicy = iota
+	// OverflowReject makes Submit fail with ErrQueueFull
+	OverflowReject
+	// OverflowDropOldest discards the earliest queued job to make room; its
+	// future resolves with ErrDropped
+	OverflowDropOldest
+	// OverflowDropNewest discards the submitted job; the returned future is
+	// already resolved with ErrDropped
+	OverflowDropNewest
+	// OverflowCallerRuns runs the job on the submitting goroutine, which
+	// naturally slows the producer down
+	OverflowCallerRuns
+)
+
+var (
+	// ErrQueueFull is returned by Submit under OverflowReject
+	ErrQueueFull = errors.New("workerpool: queue is full")
+
+	// ErrDropped is the result of jobs discarded by a drop overflow policy
+	ErrDropped = errors.New("workerpool: job was dropped because the queue was full")
+)
+
+// String returns a readable name for the policy
+func (o OverflowPolicy) String() string {
+	switch o {
+	case OverflowBlock:
+		return "block"
+	case OverflowReject:
+		return "reject"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowCallerRuns:
+		return "caller-runs"
+	default:
+		return "unknown"
+	}
+}
+
+// WithOverflow selects what Submit does when the queue is full
+func WithOverflow(policy OverflowPolicy) Option {
+	return func(c *config) {
+		c.overflow = policy
+	}
+}
+
+// waitForSpaceLocked blocks until there is room for t (see fullLocked) or
+// the pool is closed. The caller must hold mu; it is released while waiting
+// and held again on return.
+func (p *Pool[J, R]) waitForSpaceLocked(ctx context.Context, t *task[J, R]) error {
+	if p.fullLocked(t) {
+		p.blocked.Add(1)
+	}
+
+	for {
+		// Register before checking, so a worker that pops without holding mu
+		// either sees the waiter or leaves room that the check below sees
+		p.spaceWaiters.Add(1)
+		if !p.fullLocked(t) || p.closed.Load() {
+			p.spaceWaiters.Add(-1)
+			return nil
+		}
+
+		// Wait for a worker to take a job from the queue, or for a lane to
+		// move on
+		space := p.space
+		p.mu.Unlock()
+
+		var err error
+		select {
+		case <-space:
+		case <-p.closing:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		p.mu.Lock()
+		p.spaceWaiters.Add(-1)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runInCaller runs a task on the submitting goroutine. The caller must have
+// already counted the task as in flight.
+func (p *Pool[J, R]) runInCaller(t *task[J, R]) {
+	// A stuck handler has no worker to replace here, so the overrun result
+	// is ignored; the caller has simply waited out the timeout
+	p.run(p.ctx, t)
+	p.release()
+}
@@ -0,0 +1,289 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBatchSize is returned for every job of a batch whose handler returned
+// a different number of results than it was given jobs
+var ErrBatchSize = errors.New("workerpool: batch handler returned wrong number of results")
+
+// BatchHandler processes a batch of jobs and returns one result per job, in
+// the same order. Returning a BatchError fails only the listed jobs; any
+// other error fails the whole batch.
+type BatchHandler[J, R any] func(ctx context.Context, jobs []J) ([]R, error)
+
+// BatchError reports a partial batch failure: the error of each failed job,
+// keyed by its index in the batch. Jobs not listed succeeded. A partial
+// failure is not retried, since retrying would repeat the jobs that worked.
+type BatchError map[int]error
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("workerpool: %d jobs in batch failed", len(e))
+}
+
+// BatchStats counts what a Batcher has done so far
+type BatchStats struct {
+	Batches int64 // batches handed to workers
+	Jobs    int64 // jobs in those batches
+	Full    int64 // batches sent because they reached the max size
+	Timeout int64 // batches sent because the oldest job waited max wait
+	Flushed int64 // batches sent by Flush or Close
+}
+
+// batchItem is a job waiting in a batch together with its future
+type batchItem[J, R any] struct {
+	job    J
+	future *Future[R]
+}
+
+// Batcher groups submitted jobs into batches and runs each batch on a pool
+// of workers, splitting the results back to the individual futures
+type Batcher[J, R any] struct {
+	pool    *Pool[[]J, []R]
+	clock   Clock
+	maxSize int
+	maxWait time.Duration
+	wg      sync.WaitGroup // batches taken and not yet split
+
+	mu      sync.Mutex
+	items   []batchItem[J, R]
+	started time.Time // when the first job of the current batch arrived
+	gen     uint64    // bumped every time a batch is sent
+	closed  bool
+	nextID  uint64
+
+	arm     chan struct{} // tells the timer goroutine a new batch started
+	closing chan struct{}
+	stopped chan struct{}
+
+	batches, jobs, full, timeout, flushed atomic.Int64
+}
+
+// NewBatcher creates a batcher that sends a batch to one of numWorkers
+// workers once it holds maxSize jobs or its oldest job has waited maxWait.
+// Pool options such as WithRetry or WithQueueSize apply to whole batches.
+func NewBatcher[J, R any](numWorkers int, handler BatchHandler[J, R], maxSize int, maxWait time.Duration, opts ...Option) *Batcher[J, R] {
+	if maxSize < 1 {
+		panic("workerpool: maxSize must be at least 1")
+	}
+
+	b := &Batcher[J, R]{
+		maxSize: maxSize,
+		maxWait: maxWait,
+		arm:     make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	b.pool = New(numWorkers, func(ctx context.Context, jobs []J) ([]R, error) {
+		values, err := handler(ctx, jobs)
+		var partial BatchError
+		switch {
+		case err != nil && !errors.As(err, &partial):
+			return nil, err
+		case len(values) != len(jobs):
+			return nil, Permanent(ErrBatchSize)
+		case partial != nil:
+			return values, Permanent(partial)
+		}
+		return values, nil
+	}, opts...)
+	b.clock = b.pool.clock
+
+	go b.timer()
+	return b
+}
+
+// Submit adds job to the current batch and returns a future for its own
+// result. When the batch becomes full it is sent right away, which blocks
+// until the pool's queue has room. If ctx is done first, Submit returns its
+// error and job is left out; the rest of the batch belongs to other callers
+// and is still sent in the background.
+func (b *Batcher[J, R]) Submit(ctx context.Context, job J) (*Future[R], error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+
+	b.nextID++
+	item := batchItem[J, R]{job: job, future: newFuture[R](b.nextID)}
+	b.items = append(b.items, item)
+	if len(b.items) == 1 {
+		// First job of a new batch: start its max-wait timer
+		b.started = b.clock.Now()
+		select {
+		case b.arm <- struct{}{}:
+		default:
+		}
+	}
+
+	var batch []batchItem[J, R]
+	if len(b.items) >= b.maxSize {
+		batch = b.takeLocked()
+	}
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.full.Add(1)
+		if err := b.send(ctx, batch); err != nil {
+			rest := slices.DeleteFunc(batch, func(other batchItem[J, R]) bool {
+				return other.future == item.future
+			})
+			if len(rest) == 0 {
+				b.wg.Done()
+			} else {
+				go b.send(context.Background(), rest)
+			}
+			return nil, err
+		}
+	}
+	return item.future, nil
+}
+
+// Flush sends the current batch now, however small
+func (b *Batcher[J, R]) Flush() {
+	b.mu.Lock()
+	batch := b.takeLocked()
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.flushed.Add(1)
+		b.send(context.Background(), batch)
+	}
+}
+
+// Close sends the last batch, waits until every batch is done and closes
+// the pool
+func (b *Batcher[J, R]) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		<-b.stopped
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.closing)
+	b.Flush()
+	b.wg.Wait()
+	b.pool.Close()
+	close(b.stopped)
+}
+
+// Stats returns counters for the batches sent so far
+func (b *Batcher[J, R]) Stats() BatchStats {
+	return BatchStats{
+		Batches: b.batches.Load(),
+		Jobs:    b.jobs.Load(),
+		Full:    b.full.Load(),
+		Timeout: b.timeout.Load(),
+		Flushed: b.flushed.Load(),
+	}
+}
+
+// takeLocked removes and returns the current batch, or nil when it is
+// empty. The caller must hold mu.
+func (b *Batcher[J, R]) takeLocked() []batchItem[J, R] {
+	if len(b.items) == 0 {
+		return nil
+	}
+	batch := b.items
+	b.items = nil
+	b.gen++
+	b.wg.Add(1) // Done once the batch's futures are resolved
+	return batch
+}
+
+// timer sends a batch once its oldest job has waited maxWait
+func (b *Batcher[J, R]) timer() {
+	for {
+		select {
+		case <-b.arm:
+		case <-b.closing:
+			return
+		}
+
+		// Find the deadline of the batch that is filling up now
+		b.mu.Lock()
+		gen, deadline, empty := b.gen, b.started.Add(b.maxWait), len(b.items) == 0
+		b.mu.Unlock()
+		if empty {
+			continue
+		}
+
+		t := b.clock.NewTimer(deadline.Sub(b.clock.Now()))
+		select {
+		case <-t.C():
+		case <-b.closing:
+			t.Stop()
+			return
+		}
+
+		// Send it unless it was already sent because it filled up
+		b.mu.Lock()
+		var batch []batchItem[J, R]
+		if b.gen == gen {
+			batch = b.takeLocked()
+		}
+		b.mu.Unlock()
+		if batch != nil {
+			b.timeout.Add(1)
+			b.send(context.Background(), batch)
+		}
+	}
+}
+
+// send hands a batch to the pool and splits its result to the job futures
+// once it is done. It only fails when ctx is done before the pool takes the
+// batch; the batch is then left to the caller.
+func (b *Batcher[J, R]) send(ctx context.Context, batch []batchItem[J, R]) error {
+	jobs := make([]J, len(batch))
+	for i, item := range batch {
+		jobs[i] = item.job
+	}
+
+	future, err := b.pool.Submit(ctx, jobs)
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+	b.batches.Add(1)
+	b.jobs.Add(int64(len(batch)))
+	if err != nil {
+		split(batch, nil, err, 0)
+		b.wg.Done()
+		return nil
+	}
+
+	go func() {
+		defer b.wg.Done()
+		values, err := future.Wait()
+		split(batch, values, err, future.Duration())
+	}()
+	return nil
+}
+
+// split resolves each job's future from the batch result
+func split[J, R any](batch []batchItem[J, R], values []R, err error, took time.Duration) {
+	var partial BatchError
+	errors.As(err, &partial)
+
+	for i, item := range batch {
+		item.future.duration = took
+		switch {
+		case partial != nil && partial[i] != nil:
+			item.future.resolve(*new(R), partial[i])
+		case partial == nil && err != nil:
+			item.future.resolve(*new(R), err)
+		default:
+			item.future.resolve(values[i], nil)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockedBatcher is a one-worker batcher of size 2 whose first two batches
+// hold the worker and the one queue slot until release is closed, so the
+// next full batch has to wait for room. ran lists every job handled.
+func blockedBatcher(t *testing.T) (b *Batcher[int, int], release chan struct{}, ran func() []int) {
+	t.Helper()
+	release = make(chan struct{})
+	var mu sync.Mutex
+	var jobs []int
+	b = NewBatcher(1, func(ctx context.Context, batch []int) ([]int, error) {
+		<-release
+		mu.Lock()
+		jobs = append(jobs, batch...)
+		mu.Unlock()
+		return batch, nil
+	}, 2, time.Hour, WithQueueSize(1))
+
+	for job := range 4 {
+		if _, err := b.Submit(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b, release, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Sorted(slices.Values(jobs))
+	}
+}
+
+func TestBatchSubmitHonoursContext(t *testing.T) {
+	b, release, ran := blockedBatcher(t)
+	mine, err := b.Submit(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another caller fills the batch and gives up while it waits for room
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	submitted := make(chan error)
+	go func() {
+		_, err := b.Submit(ctx, 5)
+		submitted <- err
+	}()
+	select {
+	case err := <-submitted:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("submit of the filling job: err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit ignored its context while the queue was full")
+	}
+
+	// The rest of the batch still runs, without the job that gave up
+	close(release)
+	if value, err := mine.Wait(); err != nil || value != 4 {
+		t.Fatalf("job 4 = %d, %v; want 4, nil", value, err)
+	}
+	b.Close()
+	if got, want := ran(), []int{0, 1, 2, 3, 4}; !slices.Equal(got, want) {
+		t.Fatalf("ran %v, want %v", got, want)
+	}
+}
+
+func TestBatchSubmitWaitsForRoom(t *testing.T) {
+	b, release, ran := blockedBatcher(t)
+	mine, err := b.Submit(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submitted := make(chan error)
+	go func() {
+		_, err := b.Submit(context.Background(), 5)
+		submitted <- err
+	}()
+	select {
+	case err := <-submitted:
+		t.Fatalf("Submit returned %v while the queue was full", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-submitted; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mine.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+	if got, want := ran(), []int{0, 1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Fatalf("ran %v, want %v", got, want)
+	}
+}
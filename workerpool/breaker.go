@@ -0,0 +1,353 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCircuit is the circuit of jobs submitted without WithCircuit
+const DefaultCircuit = "default"
+
+// ErrCircuitOpen is matched by the error of jobs rejected by an open circuit
+var ErrCircuitOpen = errors.New("workerpool: circuit open")
+
+// Breaker configures the circuit breakers of a pool; see WithBreaker. Zero
+// fields take their defaults.
+type Breaker struct {
+	Window      time.Duration // how far back calls are counted (default 10s)
+	MinRequests int           // calls in the window before the failure rate counts (default 10)
+	FailureRate float64       // fraction of failed calls that opens the circuit, 0..1 (default 0.5)
+	CoolDown    time.Duration // how long the circuit stays open before a trial call (default 30s)
+	Probes      int           // trial calls in half-open; all must succeed to close (default 1)
+
+	// IsFailure decides whether a handler error counts against the circuit.
+	// When nil, every error does.
+	IsFailure func(error) bool
+}
+
+// withDefaults fills in the zero fields
+func (b Breaker) withDefaults() Breaker {
+	if b.Window <= 0 {
+		b.Window = 10 * time.Second
+	}
+	if b.MinRequests <= 0 {
+		b.MinRequests = 10
+	}
+	if b.FailureRate <= 0 || b.FailureRate > 1 {
+		b.FailureRate = 0.5
+	}
+	if b.CoolDown <= 0 {
+		b.CoolDown = 30 * time.Second
+	}
+	if b.Probes <= 0 {
+		b.Probes = 1
+	}
+	return b
+}
+
+// WithBreaker puts a circuit breaker in front of the handler. Each circuit
+// (DefaultCircuit unless a job names another with WithCircuit) opens once
+// its failure rate over the window reaches the threshold. While it is open,
+// jobs for it fail with a *CircuitOpenError without calling the handler,
+// which counts as an attempt: they are retried under WithRetry, no earlier
+// than the end of the cool-down, and dead-lettered once out of attempts.
+// After the cool-down the circuit is half-open and lets Probes jobs
+// through; it closes when they all succeed and opens again when one fails.
+// Every change of state is published as an event.
+func WithBreaker(b Breaker) Option {
+	return func(c *config) {
+		b = b.withDefaults()
+		c.breaker = &b
+	}
+}
+
+// WithCircuit runs the job through the named circuit (default
+// DefaultCircuit), e.g. one per downstream dependency; see WithBreaker
+func WithCircuit(name string) SubmitOption {
+	return func(c *submitConfig) {
+		c.circuit = name
+	}
+}
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // calls go through
+	CircuitOpen                         // calls are rejected
+	CircuitHalfOpen                     // a few trial calls go through
+)
+
+// String returns a readable name for the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitOpenError is the error of a job rejected by an open circuit
+type CircuitOpenError struct {
+	Circuit    string
+	RetryAfter time.Duration // time left until the circuit lets a trial call through
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("workerpool: circuit %q open, retry after %v", e.Circuit, e.RetryAfter)
+}
+
+// Unwrap makes the error match ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// CircuitStats is a snapshot of one circuit
+type CircuitStats struct {
+	State    CircuitState
+	Requests int // calls in the current window
+	Failures int // failed calls in the current window
+	Rejected int // jobs rejected while the circuit was not closed
+	Opened   int // times the circuit opened
+}
+
+// Circuits returns a snapshot of every circuit used so far, or nil when the
+// pool was not created with WithBreaker
+func (p *Pool[J, R]) Circuits() map[string]CircuitStats {
+	if p.breakers == nil {
+		return nil
+	}
+	return p.breakers.stats(p.clock.Now())
+}
+
+// breakerBuckets is how many slices the window is counted in
+const breakerBuckets = 10
+
+// bucket counts the calls made in one slice of the window
+type bucket struct {
+	index    int64 // which slice since the circuit's epoch
+	requests int
+	failures int
+}
+
+// circuit is the breaker of one circuit
+type circuit struct {
+	name  string
+	state CircuitState
+	gen   int // bumped on every change of state
+
+	epoch   time.Time // start of the first slice
+	buckets [breakerBuckets]bucket
+	openAt  time.Time // when the circuit opened
+	probes  int       // trial calls in flight while half-open
+	passed  int       // trial calls that succeeded while half-open
+
+	rejected int
+	opened   int
+}
+
+// breakers holds the circuits of a pool
+type breakers struct {
+	cfg   Breaker
+	width time.Duration // length of one slice of the window
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func newBreakers(cfg Breaker) *breakers {
+	return &breakers{
+		cfg:      cfg,
+		width:    max(cfg.Window/breakerBuckets, 1),
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// circuitLocked returns the circuit of name, creating it on first use. The
+// caller must hold mu.
+func (b *breakers) circuitLocked(name string, now time.Time) *circuit {
+	if name == "" {
+		name = DefaultCircuit
+	}
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{name: name, epoch: now}
+		b.circuits[name] = c
+	}
+	return c
+}
+
+// allow reports whether a call may go through the circuit of name. It
+// returns the rejection error otherwise, the circuit's generation to pass
+// to done, and the event to publish when the circuit changed state.
+func (b *breakers) allow(name string, now time.Time) (int, *CircuitOpenError, *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuitLocked(name, now)
+	var event *Event
+	if c.state == CircuitOpen && now.Sub(c.openAt) >= b.cfg.CoolDown {
+		event = b.setLocked(c, CircuitHalfOpen, now, "cool-down over, letting trial calls through")
+	}
+
+	switch {
+	case c.state == CircuitClosed:
+	case c.state == CircuitHalfOpen && c.probes+c.passed < b.cfg.Probes:
+		c.probes++
+	default:
+		c.rejected++
+		retryAfter := max(c.openAt.Add(b.cfg.CoolDown).Sub(now), 0)
+		return c.gen, &CircuitOpenError{Circuit: c.name, RetryAfter: retryAfter}, event
+	}
+	return c.gen, nil, event
+}
+
+// done records the outcome of a call allowed by allow in generation gen.
+// Calls that outlived their generation are ignored, and a call whose context
+// was cancelled says nothing about the dependency and only frees its trial
+// slot. It returns the event to publish when the circuit changed state.
+func (b *breakers) done(name string, gen int, err error, cancelled bool, now time.Time) *Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuitLocked(name, now)
+	if c.gen != gen {
+		return nil
+	}
+	failed := err != nil && (b.cfg.IsFailure == nil || b.cfg.IsFailure(err))
+
+	switch c.state {
+	case CircuitHalfOpen:
+		c.probes--
+		switch {
+		case cancelled:
+		case failed:
+			return b.setLocked(c, CircuitOpen, now, fmt.Sprintf("trial call failed: %v", err))
+		default:
+			c.passed++
+			if c.passed >= b.cfg.Probes {
+				return b.setLocked(c, CircuitClosed, now, fmt.Sprintf("%d trial calls succeeded", c.passed))
+			}
+		}
+
+	case CircuitClosed:
+		if cancelled {
+			return nil
+		}
+		bk := c.bucketLocked(now, b.width)
+		bk.requests++
+		if failed {
+			bk.failures++
+		}
+		requests, failures := c.countsLocked(now, b.width)
+		if failed && requests >= b.cfg.MinRequests && float64(failures) >= b.cfg.FailureRate*float64(requests) {
+			return b.setLocked(c, CircuitOpen, now, fmt.Sprintf("%d of %d calls failed in %v", failures, requests, b.cfg.Window))
+		}
+	}
+	return nil
+}
+
+// setLocked moves c to state and returns the event announcing it. The
+// caller must hold mu.
+func (b *breakers) setLocked(c *circuit, state CircuitState, now time.Time, reason string) *Event {
+	c.state = state
+	c.gen++
+	c.probes, c.passed = 0, 0
+	switch state {
+	case CircuitOpen:
+		c.openAt = now
+		c.opened++
+	case CircuitClosed:
+		// Start counting afresh
+		c.epoch = now
+		c.buckets = [breakerBuckets]bucket{}
+	}
+
+	event := &Event{Type: EventCircuitClosed, Circuit: c.name, Reason: reason}
+	switch state {
+	case CircuitOpen:
+		event.Type = EventCircuitOpen
+	case CircuitHalfOpen:
+		event.Type = EventCircuitHalfOpen
+	}
+	return event
+}
+
+// bucketLocked returns the slice now falls in, emptying it when it last
+// counted an older slice
+func (c *circuit) bucketLocked(now time.Time, width time.Duration) *bucket {
+	i := int64(now.Sub(c.epoch) / width)
+	bk := &c.buckets[i%breakerBuckets]
+	if bk.index != i {
+		*bk = bucket{index: i}
+	}
+	return bk
+}
+
+// countsLocked sums the calls of the slices still in the window
+func (c *circuit) countsLocked(now time.Time, width time.Duration) (requests, failures int) {
+	i := int64(now.Sub(c.epoch) / width)
+	for _, bk := range c.buckets {
+		if bk.index > i-breakerBuckets && bk.index <= i {
+			requests += bk.requests
+			failures += bk.failures
+		}
+	}
+	return requests, failures
+}
+
+// stats returns a snapshot of every circuit
+func (b *breakers) stats(now time.Time) map[string]CircuitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make(map[string]CircuitStats, len(b.circuits))
+	for name, c := range b.circuits {
+		s := CircuitStats{State: c.state, Rejected: c.rejected, Opened: c.opened}
+		if c.state == CircuitOpen && now.Sub(c.openAt) >= b.cfg.CoolDown {
+			s.State = CircuitHalfOpen // The next call will be a trial
+		}
+		if c.state == CircuitClosed {
+			s.Requests, s.Failures = c.countsLocked(now, b.width)
+		}
+		stats[name] = s
+	}
+	return stats
+}
+
+// guardedCall runs one attempt of t through its circuit. While the circuit
+// is open it rejects the attempt without calling the handler and reports
+// rejected.
+func (p *Pool[J, R]) guardedCall(ctx context.Context, t *task[J, R]) (out outcome[R], overran, rejected bool) {
+	if p.breakers == nil {
+		out, overran = p.call(ctx, t)
+		return out, overran, false
+	}
+
+	gen, rejection, event := p.breakers.allow(t.circuit, p.clock.Now())
+	p.publishCircuit(event)
+	if rejection != nil {
+		return outcome[R]{err: rejection}, false, true
+	}
+
+	out, overran = p.call(ctx, t)
+	p.publishCircuit(p.breakers.done(t.circuit, gen, out.err, ctx.Err() != nil, p.clock.Now()))
+	return out, overran, false
+}
+
+// publishCircuit publishes a circuit's change of state, if any
+func (p *Pool[J, R]) publishCircuit(e *Event) {
+	if e == nil {
+		return
+	}
+	p.workersMu.Lock()
+	e.Workers = p.workers
+	p.workersMu.Unlock()
+	p.publish(*e)
+}
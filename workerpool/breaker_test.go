@@ -0,0 +1,234 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("dependency down")
+
+// testBreakers returns breakers for one circuit on a fake clock
+func testBreakers(cfg Breaker) (*breakers, *FakeClock) {
+	return newBreakers(cfg.withDefaults()), NewFakeClock(epoch)
+}
+
+// call runs one call through the default circuit: allowed calls finish at
+// once with err. It returns the rejection, if any, and every event.
+func (b *breakers) call(clock *FakeClock, err error) (*CircuitOpenError, []EventType) {
+	var events []EventType
+	gen, rejection, event := b.allow("", clock.Now())
+	if event != nil {
+		events = append(events, event.Type)
+	}
+	if rejection != nil {
+		return rejection, events
+	}
+	if event := b.done("", gen, err, false, clock.Now()); event != nil {
+		events = append(events, event.Type)
+	}
+	return nil, events
+}
+
+// state returns the default circuit's state as Circuits would report it
+func (b *breakers) state(clock *FakeClock) CircuitState {
+	return b.stats(clock.Now())[DefaultCircuit].State
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	b, clock := testBreakers(Breaker{MinRequests: 4, FailureRate: 0.5, CoolDown: 30 * time.Second, Probes: 2})
+
+	// Too few calls to judge, then 4 of 5 failed
+	for _, err := range []error{errDown, errDown, errDown, nil} {
+		if _, events := b.call(clock, err); len(events) != 0 {
+			t.Fatalf("events %v before MinRequests calls", events)
+		}
+	}
+	if _, events := b.call(clock, errDown); !slices.Equal(events, []EventType{EventCircuitOpen}) {
+		t.Fatalf("events %v, want the circuit to open", events)
+	}
+
+	// Open: rejected with the time left
+	clock.Advance(10 * time.Second)
+	rejection, _ := b.call(clock, nil)
+	if rejection == nil || rejection.RetryAfter != 20*time.Second || !errors.Is(rejection, ErrCircuitOpen) {
+		t.Fatalf("rejection %v, want one with 20s left", rejection)
+	}
+
+	// Half-open: two probes, no more
+	clock.Advance(20 * time.Second)
+	if got := b.state(clock); got != CircuitHalfOpen {
+		t.Fatalf("state after the cool-down = %v, want half-open", got)
+	}
+	gen1, rejection, event := b.allow("", clock.Now())
+	if rejection != nil || event == nil || event.Type != EventCircuitHalfOpen {
+		t.Fatalf("first probe: rejection %v, event %v", rejection, event)
+	}
+	gen2, rejection, _ := b.allow("", clock.Now())
+	if rejection != nil {
+		t.Fatalf("second probe rejected: %v", rejection)
+	}
+	if _, rejection, _ := b.allow("", clock.Now()); rejection == nil || rejection.RetryAfter != 0 {
+		t.Fatalf("third concurrent call: rejection %v, want one with no time left", rejection)
+	}
+
+	// A passed probe does not free its slot for another
+	if event := b.done("", gen1, nil, false, clock.Now()); event != nil {
+		t.Fatalf("event %v after one of two probes passed", event.Type)
+	}
+	if _, rejection, _ := b.allow("", clock.Now()); rejection == nil {
+		t.Fatal("call allowed after both probe slots were used")
+	}
+	if event := b.done("", gen2, nil, false, clock.Now()); event == nil || event.Type != EventCircuitClosed {
+		t.Fatalf("event %v after both probes passed, want closed", event)
+	}
+
+	// Closed counts afresh
+	if _, events := b.call(clock, errDown); len(events) != 0 {
+		t.Fatalf("one failure after closing: events %v", events)
+	}
+	stats := b.stats(clock.Now())[DefaultCircuit]
+	if stats.State != CircuitClosed || stats.Requests != 1 || stats.Opened != 1 || stats.Rejected != 3 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	b, clock := testBreakers(Breaker{MinRequests: 1, CoolDown: time.Minute})
+	b.call(clock, errDown)
+	clock.Advance(time.Minute)
+
+	_, events := b.call(clock, errDown)
+	if want := []EventType{EventCircuitHalfOpen, EventCircuitOpen}; !slices.Equal(events, want) {
+		t.Fatalf("events %v, want %v", events, want)
+	}
+	// The cool-down starts over
+	if rejection, _ := b.call(clock, nil); rejection == nil || rejection.RetryAfter != time.Minute {
+		t.Fatalf("rejection %v, want a full cool-down left", rejection)
+	}
+}
+
+func TestBreakerCancelledProbeFreesSlot(t *testing.T) {
+	b, clock := testBreakers(Breaker{MinRequests: 1, CoolDown: time.Minute})
+	b.call(clock, errDown)
+	clock.Advance(time.Minute)
+
+	gen, _, _ := b.allow("", clock.Now())
+	if event := b.done("", gen, context.Canceled, true, clock.Now()); event != nil {
+		t.Fatalf("cancelled probe changed the state: %v", event.Type)
+	}
+	if _, events := b.call(clock, nil); !slices.Equal(events, []EventType{EventCircuitClosed}) {
+		t.Fatalf("events %v, want the next probe to close the circuit", events)
+	}
+}
+
+func TestBreakerIgnoresStaleDone(t *testing.T) {
+	b, clock := testBreakers(Breaker{MinRequests: 1, CoolDown: time.Minute})
+
+	// A slow call starts while closed and finishes after the circuit opened
+	// and went half-open
+	slow, _, _ := b.allow("", clock.Now())
+	b.call(clock, errDown)
+	clock.Advance(time.Minute)
+	probe, _, _ := b.allow("", clock.Now())
+
+	if event := b.done("", slow, nil, false, clock.Now()); event != nil {
+		t.Fatalf("stale success changed the state: %v", event.Type)
+	}
+	if _, rejection, _ := b.allow("", clock.Now()); rejection == nil {
+		t.Fatal("stale success freed the probe slot")
+	}
+	if event := b.done("", probe, nil, false, clock.Now()); event == nil || event.Type != EventCircuitClosed {
+		t.Fatalf("event %v after the probe passed, want closed", event)
+	}
+
+	// A stale failure does not count against the closed circuit either
+	if event := b.done("", slow, errDown, false, clock.Now()); event != nil {
+		t.Fatalf("stale failure changed the state: %v", event.Type)
+	}
+	if stats := b.stats(clock.Now())[DefaultCircuit]; stats.Requests != 0 {
+		t.Fatalf("stale failure was counted: %+v", stats)
+	}
+}
+
+func TestBreakerWindowForgetsOldCalls(t *testing.T) {
+	b, clock := testBreakers(Breaker{Window: 10 * time.Second, MinRequests: 5})
+	for range 4 {
+		b.call(clock, errDown)
+	}
+	clock.Advance(11 * time.Second)
+	if _, events := b.call(clock, errDown); len(events) != 0 {
+		t.Fatalf("events %v, want calls outside the window forgotten", events)
+	}
+}
+
+func TestBreakerEventsInOrder(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	var healthy atomic.Bool
+	var calls atomic.Int64
+	var mu sync.Mutex
+	var events []Event
+	pool := New(1, func(ctx context.Context, job int) (int, error) {
+		calls.Add(1)
+		if !healthy.Load() {
+			return 0, errDown
+		}
+		return job, nil
+	}, WithClock(clock), WithBreaker(Breaker{MinRequests: 2, CoolDown: time.Minute}), WithEvents(func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	defer pool.Close()
+
+	run := func(job int) error {
+		t.Helper()
+		future, err := pool.Submit(context.Background(), job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = future.Wait()
+		return err
+	}
+
+	run(1)
+	run(2) // Opens
+	if err := run(3); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("job on an open circuit: err = %v after %d handler calls", err, calls.Load())
+	}
+	clock.Advance(time.Minute)
+	run(4) // Failed probe
+	clock.Advance(time.Minute)
+	healthy.Store(true)
+	if err := run(5); err != nil {
+		t.Fatalf("probe on a healthy dependency: %v", err)
+	}
+
+	want := []struct {
+		typ EventType
+		at  time.Duration
+	}{
+		{EventCircuitOpen, 0},
+		{EventCircuitHalfOpen, time.Minute},
+		{EventCircuitOpen, time.Minute},
+		{EventCircuitHalfOpen, 2 * time.Minute},
+		{EventCircuitClosed, 2 * time.Minute},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		if e.Type != want[i].typ || !e.Time.Equal(epoch.Add(want[i].at)) || e.Circuit != DefaultCircuit {
+			t.Errorf("event %d: %v of %q at %v, want %v at +%v", i, e.Type, e.Circuit, e.Time, want[i].typ, want[i].at)
+		}
+	}
+	if stats := pool.Circuits()[DefaultCircuit]; stats.State != CircuitClosed || stats.Opened != 2 || stats.Rejected != 1 {
+		t.Fatalf("circuit stats %+v", stats)
+	}
+}
@@ -0,0 +1,398 @@
+package workerpool
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates timers. Everything time-dependent in the
+// pool (timeouts, retry backoff, autoscaling, idle workers, schedules,
+// batching, latency) goes through it, so passing a FakeClock through
+// WithClock lets tests drive all of it without real sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls f after d, never on the goroutine that called
+	// AfterFunc. The timer's C is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the part of time.Timer the pool needs
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the part of time.Ticker the pool needs
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// clockKey is the context key for the clock of the pool running a job
+type clockKey struct{}
+
+// ClockFrom returns the clock of the pool whose handler received ctx, or the
+// real clock outside a pool
+func ClockFrom(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return realClock{}
+}
+
+// Sleep pauses for d on the clock of the pool running the job (see
+// ClockFrom). It returns ctx's error if ctx is done first. Handlers that
+// sleep this way finish instantly under a FakeClock that is advanced.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := ClockFrom(ctx).NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RealClock returns the Clock backed by package time
+func RealClock() Clock {
+	return realClock{}
+}
+
+// realClock is the wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+// realTimer adapts time.Timer to Timer
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTimer) Stop() bool {
+	return r.t.Stop()
+}
+
+func (r realTimer) Reset(d time.Duration) bool {
+	return r.t.Reset(d)
+}
+
+// realTicker adapts time.Ticker to Ticker
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTicker) Stop() {
+	r.t.Stop()
+}
+
+func (r realTicker) Reset(d time.Duration) {
+	r.t.Reset(d)
+}
+
+// FakeClock is a Clock that only moves when Advance or Set is called
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a fake clock that starts at now
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a timer that fires once the fake time reaches now+d
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.scheduleLocked(t, d)
+	return t
+}
+
+// NewTicker creates a ticker that fires every d of fake time. Like a real
+// ticker it drops ticks nobody received, so one Advance delivers at most
+// one tick.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("workerpool: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1), period: d}
+	c.scheduleLocked(t, d)
+	return fakeTicker{t}
+}
+
+// AfterFunc calls f once the fake time reaches now+d. Advance, Set and
+// AdvanceToNext call it themselves before they return, so whatever f does
+// (a retry being queued, a context timing out) has happened by then. With
+// d <= 0 it runs on its own goroutine, like time.AfterFunc.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, fn: f}
+	c.scheduleLocked(t, d)
+	return t
+}
+
+// Advance moves the fake time forward by d and fires every timer that is
+// due, running due AfterFunc callbacks before it returns
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	due := c.setLocked(c.now.Add(d))
+	c.mu.Unlock()
+	runCallbacks(due)
+}
+
+// Set moves the fake time to t and fires every timer that is due, running
+// due AfterFunc callbacks before it returns
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	due := c.setLocked(t)
+	c.mu.Unlock()
+	runCallbacks(due)
+}
+
+// AdvanceToNext moves the fake time to the earliest pending timer and
+// fires it, returning how far the time moved (0 when nothing is pending)
+func (c *FakeClock) AdvanceToNext() time.Duration {
+	c.mu.Lock()
+	if len(c.timers) == 0 {
+		c.mu.Unlock()
+		return 0
+	}
+	next := slices.MinFunc(c.timers, func(a, b *fakeTimer) int {
+		return a.when.Compare(b.when)
+	}).when
+	d := next.Sub(c.now)
+	due := c.setLocked(next)
+	c.mu.Unlock()
+	runCallbacks(due)
+	return d
+}
+
+// runCallbacks calls the AfterFunc callbacks returned by setLocked, in order
+func runCallbacks(due []func()) {
+	for _, fn := range due {
+		fn()
+	}
+}
+
+// BlockUntil waits until at least n timers, tickers and AfterFuncs are
+// waiting to fire, so a test knows the code under test is asleep before
+// advancing the clock
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// scheduleLocked arms t to fire after d
+func (c *FakeClock) scheduleLocked(t *fakeTimer, d time.Duration) {
+	t.when = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+}
+
+// setLocked moves the time and fires due timers in deadline order. The
+// callbacks of due AfterFuncs are returned instead, for the caller to run
+// once it has released mu, since they may use the clock. Tickers are
+// rearmed for their first tick after now.
+func (c *FakeClock) setLocked(now time.Time) []func() {
+	c.now = now
+	slices.SortFunc(c.timers, func(a, b *fakeTimer) int {
+		return a.when.Compare(b.when)
+	})
+	var fns []func()
+	i := 0
+	for ; i < len(c.timers) && !c.timers[i].when.After(now); i++ {
+		if t := c.timers[i]; t.fn != nil {
+			fns = append(fns, t.fn)
+		} else {
+			t.fire(now)
+		}
+	}
+	due := slices.Clone(c.timers[:i])
+	c.timers = slices.Delete(c.timers, 0, i)
+
+	for _, t := range due {
+		if t.period > 0 {
+			for !t.when.After(now) {
+				t.when = t.when.Add(t.period)
+			}
+			c.timers = append(c.timers, t)
+		}
+	}
+	return fns
+}
+
+// removeLocked disarms t and reports whether it was waiting
+func (c *FakeClock) removeLocked(t *fakeTimer) bool {
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	return true
+}
+
+// fakeTimer is a timer, ticker or AfterFunc driven by a FakeClock
+type fakeTimer struct {
+	clock  *FakeClock
+	when   time.Time
+	ch     chan time.Time // nil for AfterFunc
+	fn     func()         // called instead of sending on ch
+	period time.Duration  // rearm interval of a ticker, else 0
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.removeLocked(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.removeLocked(t)
+	t.clock.scheduleLocked(t, d)
+	return active
+}
+
+// fire delivers the time without blocking, like time.Timer. An AfterFunc
+// only fires here when armed with d <= 0, by a caller that may hold locks
+// f needs, so f runs on its own goroutine.
+func (t *fakeTimer) fire(now time.Time) {
+	if t.fn != nil {
+		go t.fn()
+		return
+	}
+	select {
+	case t.ch <- now:
+	default:
+	}
+}
+
+// fakeTicker adapts a rearming fakeTimer to Ticker
+type fakeTicker struct {
+	t *fakeTimer
+}
+
+func (f fakeTicker) C() <-chan time.Time {
+	return f.t.ch
+}
+
+func (f fakeTicker) Stop() {
+	f.t.Stop()
+}
+
+func (f fakeTicker) Reset(d time.Duration) {
+	f.t.clock.mu.Lock()
+	defer f.t.clock.mu.Unlock()
+	f.t.period = d
+	f.t.clock.removeLocked(f.t)
+	f.t.clock.scheduleLocked(f.t, d)
+}
+
+// withTimeout is context.WithTimeout with the deadline measured on clock
+func withTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+
+	c := &timeoutCtx{Context: ctx, deadline: clock.Now().Add(d), done: make(chan struct{})}
+	timer := clock.AfterFunc(d, func() { c.cancel(context.DeadlineExceeded) })
+	stop := context.AfterFunc(ctx, func() { c.cancel(ctx.Err()) })
+	return c, func() {
+		timer.Stop()
+		stop()
+		c.cancel(context.Canceled)
+	}
+}
+
+// timeoutCtx is a context cancelled at a deadline on a non-real clock
+type timeoutCtx struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (c *timeoutCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timeoutCtx) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// cancel records err and closes done, once
+func (c *timeoutCtx) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
@@ -0,0 +1,148 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// epoch is where fake clocks in tests start
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// pending reports whether future has no result yet
+func pending[R any](future *Future[R]) bool {
+	select {
+	case <-future.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+func TestFakeClockRunsAfterFuncBeforeAdvanceReturns(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	var calls []string
+	clock.AfterFunc(2*time.Second, func() { calls = append(calls, "2s") })
+	clock.AfterFunc(time.Second, func() {
+		calls = append(calls, "1s")
+		// Callbacks may use the clock
+		clock.AfterFunc(time.Second, func() { calls = append(calls, "1s+1s") })
+	})
+
+	clock.Advance(999 * time.Millisecond)
+	if len(calls) != 0 {
+		t.Fatalf("fired early: %v", calls)
+	}
+	clock.Advance(time.Millisecond)
+	if len(calls) != 1 || calls[0] != "1s" {
+		t.Fatalf("after 1s: %v", calls)
+	}
+	clock.Advance(time.Second)
+	if len(calls) != 3 || calls[1] != "2s" || calls[2] != "1s+1s" {
+		t.Fatalf("after 2s: %v", calls)
+	}
+}
+
+func TestFakeClockStoppedAfterFuncDoesNotRun(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	var ran bool
+	timer := clock.AfterFunc(time.Second, func() { ran = true })
+	if !timer.Stop() {
+		t.Fatal("Stop of a pending AfterFunc returned false")
+	}
+	clock.Advance(time.Hour)
+	if ran {
+		t.Fatal("stopped AfterFunc ran")
+	}
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(time.Second)
+	if got := <-ticker.C(); !got.Equal(epoch.Add(time.Second)) {
+		t.Fatalf("tick at %v", got)
+	}
+	// Ticks nobody received are dropped
+	clock.Advance(5 * time.Second)
+	<-ticker.C()
+	select {
+	case got := <-ticker.C():
+		t.Fatalf("extra tick at %v", got)
+	default:
+	}
+}
+
+func TestRetryBackoffOnFakeClock(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	var attempts atomic.Int64
+	pool := New(1, func(ctx context.Context, job int) (int, error) {
+		if attempts.Add(1) < 3 {
+			return 0, errors.New("not yet")
+		}
+		return job, nil
+	}, WithClock(clock), WithRetry(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute}))
+	defer pool.Close()
+
+	future, err := pool.Submit(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First attempt failed, 1m backoff armed
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute - time.Second)
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts before the backoff is over = %d, want 1", got)
+	}
+	clock.Advance(time.Second)
+
+	// Second attempt failed, 2m backoff armed
+	clock.BlockUntil(1)
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("attempts after 1m = %d, want 2", got)
+	}
+	clock.Advance(2*time.Minute - time.Second)
+	if !pending(future) {
+		t.Fatal("job finished before its second backoff was over")
+	}
+	clock.Advance(time.Second)
+
+	if value, err := future.Wait(); err != nil || value != 7 {
+		t.Fatalf("result = %d, %v; want 7, nil", value, err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+}
+
+func TestJobTimeoutOnFakeClock(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	pool := New(1, func(ctx context.Context, job int) (int, error) {
+		if err := Sleep(ctx, time.Hour); err != nil {
+			return 0, err
+		}
+		return job, nil
+	}, WithClock(clock))
+	defer pool.Close()
+
+	future, err := pool.Submit(context.Background(), 1, WithTimeout(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The timeout and the handler's sleep are armed
+	clock.BlockUntil(2)
+	clock.Advance(30*time.Second - time.Millisecond)
+	if !pending(future) {
+		t.Fatal("job finished before its timeout")
+	}
+	clock.Advance(time.Millisecond)
+	if _, err := future.Wait(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"testing"
+	"time"
+)
+
+// at is a time on 2025-01-DD in UTC; January 2025 starts on a Wednesday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestCronNext(t *testing.T) {
+	for _, tc := range []struct {
+		spec string
+		from time.Time
+		want []time.Time // successive runs after from
+	}{
+		{"* * * * *", at(1, 0, 0), []time.Time{at(1, 0, 1), at(1, 0, 2)}},
+		{"*/15 9-17 * * MON-FRI", at(3, 17, 50), []time.Time{at(6, 9, 0), at(6, 9, 15)}},
+		{"5/20 * * * *", at(1, 0, 0), []time.Time{at(1, 0, 5), at(1, 0, 25), at(1, 0, 45), at(1, 1, 5)}},
+		{"0 8-12/2 * * *", at(1, 9, 0), []time.Time{at(1, 10, 0), at(1, 12, 0), at(2, 8, 0)}},
+		{"30 6 1,15,20-21 * *", at(10, 0, 0), []time.Time{at(15, 6, 30), at(20, 6, 30), at(21, 6, 30)}},
+		{"0 0 * * 7", at(1, 0, 0), []time.Time{at(5, 0, 0), at(12, 0, 0)}},                  // 7 is Sunday too
+		{"0 0 13 * fri", at(1, 0, 0), []time.Time{at(3, 0, 0), at(10, 0, 0), at(13, 0, 0)}}, // either day
+		{"0 0 1 feb,Mar *", at(1, 0, 0), []time.Time{
+			time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		{"@hourly", at(1, 0, 30), []time.Time{at(1, 1, 0), at(1, 2, 0)}},
+		{"@weekly", at(1, 0, 0), []time.Time{at(5, 0, 0), at(12, 0, 0)}},
+		{"0 0 29 2 *", at(1, 0, 0), []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		{"0 0 30 2 *", at(1, 0, 0), []time.Time{{}}}, // Never
+	} {
+		s, err := ParseCron(tc.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tc.spec, err)
+			continue
+		}
+		from := tc.from
+		for _, want := range tc.want {
+			got := s.Next(from)
+			if !got.Equal(want) {
+				t.Errorf("%q: Next(%v) = %v, want %v", tc.spec, from, got, want)
+				break
+			}
+			from = got
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"30-10 * * * *",
+		"1-x * * * *",
+		"* * * foo *",
+		"1,,2 * * * *",
+		"@often",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCronStepDayIsNotRestricted(t *testing.T) {
+	// */2 in day of month does not turn on the either-day rule, so only
+	// Mondays on an odd day match
+	s, err := ParseCron("0 0 */2 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Next(at(1, 0, 0)), at(13, 0, 0); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got, want)
+	}
+}
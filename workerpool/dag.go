@@ -0,0 +1,298 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrCycle is returned when a DAG's dependencies form a cycle
+	ErrCycle = errors.New("workerpool: dependency cycle")
+
+	// ErrDependencyFailed is the error of nodes skipped because an upstream
+	// node failed
+	ErrDependencyFailed = errors.New("workerpool: dependency failed")
+)
+
+// DAGFunc runs one node. inputs holds the results of the node's
+// dependencies, keyed by dependency ID.
+type DAGFunc[R any] func(ctx context.Context, inputs map[string]R) (R, error)
+
+// FailurePolicy decides what happens to the rest of a DAG when a node fails
+type FailurePolicy int
+
+const (
+	// SkipDependents skips every node that depends on the failed one, while
+	// independent branches keep running
+	SkipDependents FailurePolicy = iota
+	// CancelAll cancels running nodes and does not start any new ones
+	CancelAll
+)
+
+// NodeStatus is the final state of a DAG node
+type NodeStatus int
+
+const (
+	NodeSucceeded NodeStatus = iota
+	NodeFailed
+	NodeSkipped   // an upstream node failed
+	NodeCancelled // the run was cancelled before the node finished
+)
+
+// String returns a readable name for the status
+func (s NodeStatus) String() string {
+	switch s {
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	case NodeCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// NodeResult is the outcome of one DAG node
+type NodeResult[R any] struct {
+	Status NodeStatus
+	Value  R
+	Err    error
+}
+
+// dagNode is a job with its declared dependencies
+type dagNode[R any] struct {
+	id   string
+	fn   DAGFunc[R]
+	deps []string
+}
+
+// DAG is a set of jobs with dependencies between them. Build it with Add,
+// then execute it on a pool with Run.
+type DAG[R any] struct {
+	nodes map[string]*dagNode[R]
+	order []string // IDs in the order they were added
+}
+
+// NewDAG creates an empty DAG
+func NewDAG[R any]() *DAG[R] {
+	return &DAG[R]{nodes: make(map[string]*dagNode[R])}
+}
+
+// Add declares a node that runs fn once every node in deps has succeeded.
+// Dependencies may be added later; they are checked by Validate and Run.
+func (d *DAG[R]) Add(id string, fn DAGFunc[R], deps ...string) error {
+	if _, exists := d.nodes[id]; exists {
+		return fmt.Errorf("workerpool: duplicate DAG node %q", id)
+	}
+	d.nodes[id] = &dagNode[R]{id: id, fn: fn, deps: slices.Clone(deps)}
+	d.order = append(d.order, id)
+	return nil
+}
+
+// Validate checks that every dependency exists and that there are no cycles
+func (d *DAG[R]) Validate() error {
+	for _, id := range d.order {
+		for _, dep := range d.nodes[id].deps {
+			if _, ok := d.nodes[dep]; !ok {
+				return fmt.Errorf("workerpool: DAG node %q depends on unknown node %q", id, dep)
+			}
+		}
+	}
+
+	// Depth-first search; reaching a node that is still on the stack means
+	// we followed a cycle back to it
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int, len(d.nodes))
+	var stack []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case onStack:
+			start := slices.Index(stack, id)
+			path := append(slices.Clone(stack[start:]), id)
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+		case done:
+			return nil
+		}
+
+		state[id] = onStack
+		stack = append(stack, id)
+		for _, dep := range d.nodes[id].deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+	for _, id := range d.order {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DAGTask is the job type of a pool that runs DAG nodes
+type DAGTask[R any] struct {
+	ID     string
+	Inputs map[string]R
+
+	fn  DAGFunc[R]
+	ctx context.Context // the Run context, so CancelAll reaches running nodes
+}
+
+// DAGHandler returns the handler for a pool that runs DAG nodes, e.g.
+// workerpool.New(4, workerpool.DAGHandler[int]())
+func DAGHandler[R any]() Handler[DAGTask[R], R] {
+	return func(ctx context.Context, t DAGTask[R]) (R, error) {
+		// Stop the node when either the worker or the DAG run is cancelled
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(t.ctx, cancel)
+		defer stop()
+
+		return t.fn(ctx, t.Inputs)
+	}
+}
+
+// nodeDone reports a finished node back to Run
+type nodeDone[R any] struct {
+	id    string
+	value R
+	err   error
+}
+
+// Run executes the DAG on pool: nodes whose dependencies have all
+// succeeded are submitted as soon as they are ready, and receive their
+// dependencies' results as inputs. It returns the result of every node and
+// the first node error, if any.
+func (d *DAG[R]) Run(parent context.Context, pool *Pool[DAGTask[R], R], policy FailurePolicy) (map[string]NodeResult[R], error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	// Count unfinished dependencies and remember who depends on whom
+	waiting := make(map[string]int, len(d.nodes))
+	dependents := make(map[string][]string, len(d.nodes))
+	var ready []string
+	for _, id := range d.order {
+		deps := d.nodes[id].deps
+		waiting[id] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], id)
+		}
+		if len(deps) == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	results := make(map[string]NodeResult[R], len(d.nodes))
+	done := make(chan nodeDone[R])
+	running := 0
+	var firstErr error
+
+	// skip marks every node downstream of id with the given status
+	var skip func(id string, status NodeStatus, err error)
+	skip = func(id string, status NodeStatus, err error) {
+		for _, next := range dependents[id] {
+			if _, settled := results[next]; settled {
+				continue
+			}
+			results[next] = NodeResult[R]{Status: status, Err: err}
+			skip(next, status, err)
+		}
+	}
+
+	// submit sends a ready node to the pool with its dependencies' results
+	submit := func(id string) {
+		node := d.nodes[id]
+		inputs := make(map[string]R, len(node.deps))
+		for _, dep := range node.deps {
+			inputs[dep] = results[dep].Value
+		}
+
+		future, err := pool.Submit(ctx, DAGTask[R]{ID: id, Inputs: inputs, fn: node.fn, ctx: ctx})
+		running++
+		go func() {
+			var value R
+			if err == nil {
+				value, err = future.Wait()
+			}
+			done <- nodeDone[R]{id: id, value: value, err: err}
+		}()
+	}
+
+	for len(ready) > 0 || running > 0 {
+		for _, id := range ready {
+			if ctx.Err() != nil {
+				results[id] = NodeResult[R]{Status: NodeCancelled, Err: ctx.Err()}
+				continue
+			}
+			submit(id)
+		}
+		ready = ready[:0]
+		if running == 0 {
+			// Every ready node was cancelled and nothing is left to wait for
+			break
+		}
+
+		// Wait for the next node to finish
+		r := <-done
+		running--
+
+		if r.err == nil {
+			results[r.id] = NodeResult[R]{Status: NodeSucceeded, Value: r.value}
+			for _, next := range dependents[r.id] {
+				waiting[next]--
+				if _, settled := results[next]; !settled && waiting[next] == 0 {
+					ready = append(ready, next)
+				}
+			}
+			continue
+		}
+
+		status := NodeFailed
+		if ctx.Err() != nil && firstErr != nil {
+			// Aborted by CancelAll because of an earlier failure
+			status = NodeCancelled
+		}
+		results[r.id] = NodeResult[R]{Status: status, Err: r.err}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("workerpool: DAG node %q: %w", r.id, r.err)
+		}
+
+		if policy == CancelAll {
+			cancel()
+			skip(r.id, NodeCancelled, context.Canceled)
+		} else {
+			skip(r.id, NodeSkipped, fmt.Errorf("%w: %s", ErrDependencyFailed, r.id))
+		}
+	}
+
+	// Nodes never reached because the run was cancelled
+	for _, id := range d.order {
+		if _, settled := results[id]; !settled {
+			results[id] = NodeResult[R]{Status: NodeCancelled, Err: context.Canceled}
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = parent.Err()
+	}
+	return results, firstErr
+}
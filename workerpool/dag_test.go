@@ -0,0 +1,79 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// runDAG runs d on a fresh pool and fails the test if Run does not return
+func runDAG(t *testing.T, ctx context.Context, d *DAG[int], policy FailurePolicy) (map[string]NodeResult[int], error) {
+	t.Helper()
+	pool := New(4, DAGHandler[int]())
+	defer pool.Close()
+
+	type result struct {
+		results map[string]NodeResult[int]
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		results, err := d.Run(ctx, pool, policy)
+		done <- result{results, err}
+	}()
+	select {
+	case r := <-done:
+		return r.results, r.err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+		return nil, nil
+	}
+}
+
+// slowSuccess ignores its context and succeeds after d
+func slowSuccess(d time.Duration) DAGFunc[int] {
+	return func(context.Context, map[string]int) (int, error) {
+		time.Sleep(d)
+		return 1, nil
+	}
+}
+
+func TestDAGCancelAllWithOnlyCancelledReadyNodes(t *testing.T) {
+	boom := errors.New("boom")
+	d := NewDAG[int]()
+	d.Add("a", func(context.Context, map[string]int) (int, error) { return 0, boom })
+	d.Add("b", slowSuccess(50*time.Millisecond))
+	d.Add("c", slowSuccess(0), "b")
+
+	results, err := runDAG(t, context.Background(), d, CancelAll)
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	want := map[string]NodeStatus{"a": NodeFailed, "b": NodeSucceeded, "c": NodeCancelled}
+	for id, status := range want {
+		if got := results[id].Status; got != status {
+			t.Errorf("%s: status %v, want %v", id, got, status)
+		}
+	}
+}
+
+func TestDAGSkipDependentsParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := NewDAG[int]()
+	d.Add("b", func(context.Context, map[string]int) (int, error) {
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		return 1, nil
+	})
+	d.Add("c", slowSuccess(0), "b")
+
+	results, err := runDAG(t, ctx, d, SkipDependents)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	if got := results["c"].Status; got != NodeCancelled {
+		t.Errorf("c: status %v, want %v", got, NodeCancelled)
+	}
+}
@@ -0,0 +1,157 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DeadLetter is a job that failed on its last allowed attempt
+type DeadLetter[J any] struct {
+	ID       uint64 // ID of the original submission
+	Job      J
+	Err      error // error from the last attempt
+	Attempts int
+	FailedAt time.Time
+}
+
+// DeadLetterQueue keeps the most recent failed jobs for inspection. It is
+// safe for concurrent use.
+type DeadLetterQueue[J any] struct {
+	mu       sync.Mutex
+	items    []DeadLetter[J]
+	capacity int
+	dropped  int
+}
+
+func newDeadLetterQueue[J any](capacity int) *DeadLetterQueue[J] {
+	return &DeadLetterQueue[J]{capacity: capacity}
+}
+
+// add stores a dead letter, evicting the oldest one when full
+func (q *DeadLetterQueue[J]) add(dl DeadLetter[J]) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.capacity {
+		q.items = q.items[1:]
+		q.dropped++
+	}
+	q.items = append(q.items, dl)
+}
+
+// List returns a copy of the dead letters, oldest first
+func (q *DeadLetterQueue[J]) List() []DeadLetter[J] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]DeadLetter[J](nil), q.items...)
+}
+
+// Len returns the number of dead letters currently held
+func (q *DeadLetterQueue[J]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// Dropped returns how many dead letters were evicted because the queue was full
+func (q *DeadLetterQueue[J]) Dropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.dropped
+}
+
+// take removes and returns the dead letter with the given ID
+func (q *DeadLetterQueue[J]) take(id uint64) (DeadLetter[J], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, dl := range q.items {
+		if dl.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return dl, true
+		}
+	}
+	return DeadLetter[J]{}, false
+}
+
+// drain removes and returns every dead letter
+func (q *DeadLetterQueue[J]) drain() []DeadLetter[J] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// WithDeadLetters keeps up to capacity jobs that failed on their last
+// attempt, so they can be inspected and requeued
+func WithDeadLetters(capacity int) Option {
+	return func(c *config) {
+		c.deadLetters = capacity
+	}
+}
+
+// DeadLetters returns the pool's dead-letter queue, or nil when it was not
+// enabled with WithDeadLetters
+func (p *Pool[J, R]) DeadLetters() *DeadLetterQueue[J] {
+	return p.dead
+}
+
+// deadLetter records a task that failed for good
+func (p *Pool[J, R]) deadLetter(t *task[J, R]) {
+	if p.dead == nil {
+		return
+	}
+	p.dead.add(DeadLetter[J]{
+		ID:       t.future.id,
+		Job:      t.job,
+		Err:      t.lastErr,
+		Attempts: t.attempts,
+		FailedAt: p.clock.Now(),
+	})
+}
+
+// Requeue removes a dead letter and submits its job again as a new job
+func (p *Pool[J, R]) Requeue(ctx context.Context, id uint64, opts ...SubmitOption) (*Future[R], error) {
+	if p.dead == nil {
+		return nil, ErrNoDeadLetter
+	}
+	dl, ok := p.dead.take(id)
+	if !ok {
+		return nil, ErrNoDeadLetter
+	}
+	future, err := p.Submit(ctx, dl.Job, opts...)
+	if err != nil {
+		// Keep it for another try
+		p.dead.add(dl)
+	}
+	return future, err
+}
+
+// RequeueAll submits every dead letter again. It stops at the first submit
+// error and returns the futures created so far.
+func (p *Pool[J, R]) RequeueAll(ctx context.Context, opts ...SubmitOption) ([]*Future[R], error) {
+	if p.dead == nil {
+		return nil, nil
+	}
+
+	var futures []*Future[R]
+	items := p.dead.drain()
+	for i, dl := range items {
+		future, err := p.Submit(ctx, dl.Job, opts...)
+		if err != nil {
+			// Put back what could not be submitted
+			for _, rest := range items[i:] {
+				p.dead.add(rest)
+			}
+			return futures, err
+		}
+		futures = append(futures, future)
+	}
+	return futures, nil
+}
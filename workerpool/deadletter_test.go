@@ -0,0 +1,52 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// failedPool returns a pool whose only job ended up as a dead letter
+func failedPool(t *testing.T) (*Pool[int, int], uint64) {
+	t.Helper()
+	pool := New(1, func(ctx context.Context, job int) (int, error) {
+		return 0, errors.New("boom")
+	}, WithDeadLetters(10))
+	future, err := pool.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future.Wait()
+	if got := pool.DeadLetters().Len(); got != 1 {
+		t.Fatalf("dead letters = %d, want 1", got)
+	}
+	return pool, pool.DeadLetters().List()[0].ID
+}
+
+func TestRequeueKeepsDeadLetterWhenSubmitFails(t *testing.T) {
+	pool, id := failedPool(t)
+	pool.Close()
+
+	if _, err := pool.Requeue(context.Background(), id); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Requeue after Close: err = %v, want %v", err, ErrPoolClosed)
+	}
+	if got := pool.DeadLetters().Len(); got != 1 {
+		t.Fatalf("dead letters after failed Requeue = %d, want 1", got)
+	}
+}
+
+func TestRequeueRemovesDeadLetter(t *testing.T) {
+	pool, id := failedPool(t)
+	defer pool.Close()
+
+	future, err := pool.Requeue(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future.Wait()
+	// The job fails again and becomes a new dead letter
+	letters := pool.DeadLetters().List()
+	if len(letters) != 1 || letters[0].ID == id {
+		t.Fatalf("dead letters after Requeue = %+v, want only the new failure", letters)
+	}
+}
@@ -0,0 +1,123 @@
+package workerpool
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDedupEntries is how many completed results WithDedup keeps when
+// maxEntries is not positive
+const DefaultDedupEntries = 10000
+
+// WithDedup keeps the result of a job submitted with WithIdempotencyKey for
+// ttl after it succeeds, so a duplicate submitted within ttl gets the same
+// future back instead of running again. At most maxEntries results are kept;
+// the oldest are forgotten first. Failed jobs are never cached.
+func WithDedup(ttl time.Duration, maxEntries int) Option {
+	return func(c *config) {
+		c.dedupTTL = ttl
+		c.dedupEntries = maxEntries
+	}
+}
+
+// WithIdempotencyKey marks submissions of the same logical job. While a job
+// with the key is queued or running, a duplicate Submit joins it and returns
+// its future rather than running the job again. With WithDedup, this also
+// holds for ttl after the job succeeds.
+func WithIdempotencyKey(key string) SubmitOption {
+	return func(c *submitConfig) {
+		c.idempotencyKey = key
+	}
+}
+
+// dedupEntry is the job currently answering for an idempotency key
+type dedupEntry[R any] struct {
+	future  *Future[R]
+	expires time.Time // zero while the job has no result yet
+}
+
+// completedKey remembers when a cached result expires
+type completedKey[R any] struct {
+	key     string
+	future  *Future[R]
+	expires time.Time
+}
+
+// dedupTable maps idempotency keys to the futures answering for them
+type dedupTable[R any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*dedupEntry[R]
+
+	// completed holds cached results in the order they finished, which is
+	// also the order they expire in, since every result lives for ttl
+	completed []completedKey[R]
+}
+
+func newDedupTable[R any](ttl time.Duration, maxEntries int) *dedupTable[R] {
+	if maxEntries <= 0 {
+		maxEntries = DefaultDedupEntries
+	}
+	return &dedupTable[R]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*dedupEntry[R]),
+	}
+}
+
+// join returns the future answering for key. If there is none, f is
+// registered to answer for it and join returns false.
+func (d *dedupTable[R]) join(key string, f *Future[R], now time.Time) (*Future[R], bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expireLocked(now)
+	if e, ok := d.entries[key]; ok {
+		return e.future, true
+	}
+	d.entries[key] = &dedupEntry[R]{future: f}
+	return nil, false
+}
+
+// complete is called once f has its result. A success stays cached for ttl;
+// a failure releases the key so the job can be submitted again.
+func (d *dedupTable[R]) complete(key string, f *Future[R], err error, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.entries[key]
+	if !ok || e.future != f {
+		return
+	}
+	if err != nil || d.ttl <= 0 {
+		delete(d.entries, key)
+		return
+	}
+
+	e.expires = now.Add(d.ttl)
+	d.completed = append(d.completed, completedKey[R]{key: key, future: f, expires: e.expires})
+	for len(d.completed) > d.maxEntries {
+		d.forgetOldestLocked()
+	}
+}
+
+// expireLocked drops cached results whose ttl has passed. The caller must
+// hold mu.
+func (d *dedupTable[R]) expireLocked(now time.Time) {
+	for len(d.completed) > 0 && !now.Before(d.completed[0].expires) {
+		d.forgetOldestLocked()
+	}
+}
+
+// forgetOldestLocked drops the oldest cached result. The caller must hold mu.
+func (d *dedupTable[R]) forgetOldestLocked() {
+	oldest := d.completed[0]
+	d.completed[0] = completedKey[R]{}
+	d.completed = d.completed[1:]
+
+	// The key may already answer to a newer job
+	if e, ok := d.entries[oldest.key]; ok && e.future == oldest.future {
+		delete(d.entries, oldest.key)
+	}
+}
@@ -0,0 +1,68 @@
+package workerpool
+
+import "time"
+
+// EventType identifies what happened in an Event
+type EventType int
+
+const (
+	// EventScaleUp is published when the autoscaler adds a worker
+	EventScaleUp EventType = iota
+	// EventScaleDown is published when an idle worker is retired
+	EventScaleDown
+	// EventWorkerReplaced is published when a worker stuck on an overrunning
+	// job is replaced by a new one
+	EventWorkerReplaced
+	// EventJournalError is published when the journal cannot be written or
+	// a replayed job cannot be decoded
+	EventJournalError
+	// EventCircuitOpen is published when a circuit breaker opens and starts
+	// rejecting jobs
+	EventCircuitOpen
+	// EventCircuitHalfOpen is published when an open circuit's cool-down is
+	// over and it lets trial jobs through
+	EventCircuitHalfOpen
+	// EventCircuitClosed is published when a circuit's trial jobs succeeded
+	// and it lets every job through again
+	EventCircuitClosed
+)
+
+// String returns a readable name for the event type
+func (t EventType) String() string {
+	switch t {
+	case EventScaleUp:
+		return "scale-up"
+	case EventScaleDown:
+		return "scale-down"
+	case EventWorkerReplaced:
+		return "worker-replaced"
+	case EventJournalError:
+		return "journal-error"
+	case EventCircuitOpen:
+		return "circuit-open"
+	case EventCircuitHalfOpen:
+		return "circuit-half-open"
+	case EventCircuitClosed:
+		return "circuit-closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a notable change inside the pool
+type Event struct {
+	Type    EventType
+	Time    time.Time
+	Workers int    // worker count after the change
+	Reason  string // human readable cause
+	Circuit string // circuit name, for circuit breaker events
+}
+
+// publish delivers an event to the configured listener, if any
+func (p *Pool[J, R]) publish(e Event) {
+	if p.onEvent == nil {
+		return
+	}
+	e.Time = p.clock.Now()
+	p.onEvent(e)
+}
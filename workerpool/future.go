@@ -0,0 +1,52 @@
+package workerpool
+
+import "time"
+
+// Future is a handle to the result of a submitted job
+type Future[R any] struct {
+	id       uint64
+	done     chan struct{}
+	value    R
+	err      error
+	duration time.Duration
+	onDone   func(err error) // called once resolved, set before the job is queued
+}
+
+func newFuture[R any](id uint64) *Future[R] {
+	return &Future[R]{
+		id:   id,
+		done: make(chan struct{}),
+	}
+}
+
+// ID returns the sequence number assigned to the job when it was submitted
+func (f *Future[R]) ID() uint64 {
+	return f.id
+}
+
+// Done returns a channel that is closed once the job has finished
+func (f *Future[R]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the job has finished and returns its result
+func (f *Future[R]) Wait() (R, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// Duration returns how long the handler ran for the job (zero if it never started)
+func (f *Future[R]) Duration() time.Duration {
+	<-f.done
+	return f.duration
+}
+
+// resolve stores the result and wakes up everyone waiting on the future
+func (f *Future[R]) resolve(value R, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+	if f.onDone != nil {
+		f.onDone(err)
+	}
+}
@@ -0,0 +1,143 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Group runs jobs on a pool until one of them fails, like errgroup: the
+// first failure cancels the group's context, which stops the jobs still
+// running and keeps queued ones from starting. Wait then returns that error
+// together with any others collected while the group wound down.
+//
+//	g := workerpool.NewGroup(ctx, 4, fetch)
+//	for _, url := range urls {
+//		g.Go(url)
+//	}
+//	if err := g.Wait(); err != nil {
+//		...
+//	}
+type Group[J, R any] struct {
+	pool   *Pool[J, R]
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   func() bool // unregisters the dispatch stop
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup creates a group running up to numWorkers jobs at once. Go blocks
+// while every worker is busy and the queue is full. Handlers see a context
+// that is cancelled when the group fails or ctx is done. opts configure the
+// pool as for New.
+func NewGroup[J, R any](ctx context.Context, numWorkers int, handler Handler[J, R], opts ...Option) *Group[J, R] {
+	g := &Group[J, R]{}
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+
+	// Stop the job when either its worker or the group is cancelled
+	run := func(ctx context.Context, job J) (R, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(g.ctx, cancel)
+		defer stop()
+
+		return handler(ctx, job)
+	}
+
+	record := func(c *config) {
+		c.onResolve = g.record
+	}
+	g.pool = New(numWorkers, run, append(opts, record)...)
+	g.stop = context.AfterFunc(g.ctx, g.pool.stopDispatch)
+	return g
+}
+
+// Context returns the group's context, which is cancelled by the first
+// failure
+func (g *Group[J, R]) Context() context.Context {
+	return g.ctx
+}
+
+// Pool returns the pool behind the group, e.g. for Stats
+func (g *Group[J, R]) Pool() *Pool[J, R] {
+	return g.pool
+}
+
+// Go submits a job. Once the group has failed it returns the cause without
+// queueing the job.
+func (g *Group[J, R]) Go(job J, opts ...SubmitOption) (*Future[R], error) {
+	if g.ctx.Err() != nil {
+		return nil, context.Cause(g.ctx)
+	}
+	f, err := g.pool.Submit(g.ctx, job, opts...)
+	if err != nil && g.ctx.Err() != nil {
+		return nil, context.Cause(g.ctx) // Gave up waiting for room
+	}
+	return f, err
+}
+
+// record collects the final error of a job; the first one fails the group
+func (g *Group[J, R]) record(err error) {
+	if err == nil || errors.Is(err, ErrNotStarted) {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		return // A job stopped by the group's cancellation
+	}
+	g.errs = append(g.errs, err)
+	if len(g.errs) == 1 {
+		g.cancel(err)
+		g.pool.stopDispatch()
+	}
+}
+
+// Wait waits for every job submitted so far and releases the pool. It
+// returns a *GroupError when a job failed, ctx's cause when the group's
+// parent context was cancelled, and nil otherwise. Go must not be called
+// after Wait.
+func (g *Group[J, R]) Wait() error {
+	g.pool.Close()
+	g.stop()
+
+	g.mu.Lock()
+	errs := g.errs
+	g.mu.Unlock()
+	cause := context.Cause(g.ctx)
+	g.cancel(nil)
+
+	if len(errs) > 0 {
+		return &GroupError{Errs: errs}
+	}
+	return cause
+}
+
+// GroupError is the error of a failed group. Errs holds every job error in
+// the order the jobs finished; the first one is the failure that cancelled
+// the group.
+type GroupError struct {
+	Errs []error
+}
+
+// Error describes the first failure and counts the others
+func (e *GroupError) Error() string {
+	if len(e.Errs) == 1 {
+		return e.Errs[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more errors)", e.Errs[0], len(e.Errs)-1)
+}
+
+// First returns the error that cancelled the group
+func (e *GroupError) First() error {
+	return e.Errs[0]
+}
+
+// Unwrap returns every collected error, for errors.Is and errors.As
+func (e *GroupError) Unwrap() []error {
+	return e.Errs
+}
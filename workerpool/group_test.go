@@ -0,0 +1,138 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestGroupWaitReturnsNilOnSuccess(t *testing.T) {
+	g := NewGroup(context.Background(), 3, func(ctx context.Context, n int) (int, error) {
+		return n * 2, nil
+	})
+	var futures []*Future[int]
+	for n := range 10 {
+		f, err := g.Go(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, f)
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait = %v, want nil", err)
+	}
+	for n, f := range futures {
+		if value, err := f.Wait(); err != nil || value != n*2 {
+			t.Fatalf("job %d = %d, %v; want %d, nil", n, value, err, n*2)
+		}
+	}
+}
+
+func TestGroupFirstFailureCancelsTheRest(t *testing.T) {
+	errBad := errors.New("bad job")
+	started := make(chan struct{})
+	fail := make(chan struct{})
+	var mu sync.Mutex
+	var ran []string
+	g := NewGroup(context.Background(), 2, func(ctx context.Context, job string) (string, error) {
+		mu.Lock()
+		ran = append(ran, job)
+		mu.Unlock()
+		switch job {
+		case "slow":
+			close(started)
+			<-ctx.Done() // Stopped by the failure
+			return "", ctx.Err()
+		case "bad":
+			<-fail
+			return "", errBad
+		}
+		return job, nil
+	}, WithQueueSize(4))
+
+	slow, _ := g.Go("slow")
+	<-started
+	g.Go("bad")
+	// Both workers are busy, so these wait in the queue
+	queued1, _ := g.Go("queued-1")
+	queued2, _ := g.Go("queued-2")
+	close(fail)
+
+	<-g.Context().Done()
+	if cause := context.Cause(g.Context()); !errors.Is(cause, errBad) {
+		t.Fatalf("group cause = %v, want %v", cause, errBad)
+	}
+	if _, err := g.Go("late"); !errors.Is(err, errBad) {
+		t.Fatalf("Go after the failure: err = %v, want %v", err, errBad)
+	}
+
+	err := g.Wait()
+	var groupErr *GroupError
+	if !errors.As(err, &groupErr) {
+		t.Fatalf("Wait = %v, want a *GroupError", err)
+	}
+	// The cancelled slow job is not a failure of its own
+	if !errors.Is(groupErr.First(), errBad) || len(groupErr.Errs) != 1 {
+		t.Fatalf("group errors %v, want only %v", groupErr.Errs, errBad)
+	}
+	if _, err := slow.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("running job: err = %v, want %v", err, context.Canceled)
+	}
+	for _, f := range []*Future[string]{queued1, queued2} {
+		if _, err := f.Wait(); !errors.Is(err, ErrNotStarted) {
+			t.Fatalf("queued job: err = %v, want %v", err, ErrNotStarted)
+		}
+	}
+	slices.Sort(ran)
+	if want := []string{"bad", "slow"}; !slices.Equal(ran, want) {
+		t.Fatalf("handlers ran for %v, want only %v", ran, want)
+	}
+}
+
+func TestGroupErrorCollectsLaterFailures(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	g := NewGroup(context.Background(), 2, func(ctx context.Context, job string) (string, error) {
+		if job == "first" {
+			return "", errFirst
+		}
+		// Fails on its own after the group was cancelled
+		<-ctx.Done()
+		return "", errSecond
+	})
+	g.Go("second")
+	g.Go("first")
+
+	var groupErr *GroupError
+	if err := g.Wait(); !errors.As(err, &groupErr) {
+		t.Fatalf("Wait = %v, want a *GroupError", err)
+	}
+	if !slices.Equal(groupErr.Errs, []error{errFirst, errSecond}) {
+		t.Fatalf("Errs = %v, want [first second]", groupErr.Errs)
+	}
+	if groupErr.First() != errFirst {
+		t.Fatalf("First = %v, want %v", groupErr.First(), errFirst)
+	}
+	if !errors.Is(groupErr, errSecond) {
+		t.Fatal("errors.Is does not see the second error")
+	}
+	if got, want := groupErr.Error(), "first (and 1 more errors)"; got != want {
+		t.Fatalf("Error = %q, want %q", got, want)
+	}
+}
+
+func TestGroupParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	errStop := errors.New("stop")
+	g := NewGroup(ctx, 1, func(ctx context.Context, n int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	g.Go(1)
+	cancel(errStop)
+	if err := g.Wait(); !errors.Is(err, errStop) {
+		t.Fatalf("Wait = %v, want the parent's cause %v", err, errStop)
+	}
+}
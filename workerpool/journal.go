@@ -0,0 +1,322 @@
+package workerpool
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// DefaultCompactEvery is how many acknowledgements a journal collects
+// before it rewrites its file without the acknowledged jobs
+const DefaultCompactEvery = 1024
+
+// journal record operations
+const (
+	opEnqueue = "enqueue"
+	opAck     = "ack"
+)
+
+// journalRecord is one line of the append-only log
+type journalRecord struct {
+	Op       string          `json:"op"`
+	ID       uint64          `json:"id"`
+	Job      json.RawMessage `json:"job,omitempty"`
+	Priority int             `json:"priority,omitempty"`
+	Key      string          `json:"key,omitempty"`
+	Tenant   string          `json:"tenant,omitempty"`
+	Circuit  string          `json:"circuit,omitempty"`
+}
+
+// Journal is an append-only log file that makes a pool's queue survive
+// restarts. Every submitted job is written (and fsynced) before it is
+// queued, and acknowledged once it has a final result. Jobs that were never
+// acknowledged are replayed when a pool is created with the same journal,
+// giving at-least-once delivery. Jobs must be JSON encodable.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	pending map[uint64]journalRecord // enqueued but not yet acknowledged
+	nextID  uint64
+	acks    int // acknowledgements written since the last compaction
+
+	// CompactEvery sets how many acknowledgements trigger an automatic
+	// compaction (default DefaultCompactEvery, negative disables it)
+	CompactEvery int
+}
+
+// OpenJournal opens or creates the journal at path and loads the jobs that
+// were enqueued but never acknowledged
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{
+		path:         path,
+		pending:      make(map[uint64]journalRecord),
+		CompactEvery: DefaultCompactEvery,
+	}
+	valid, torn, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	if torn {
+		// Cut off the partial record, or the next one would be appended to
+		// it and lost on the following replay
+		if err := os.Truncate(path, valid); err != nil {
+			return nil, fmt.Errorf("workerpool: repair journal: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("workerpool: open journal: %w", err)
+	}
+	j.file = file
+	return j, nil
+}
+
+// load replays the log into the pending set. It returns the length of the
+// log up to its last complete line, and whether a torn line follows it.
+func (j *Journal) load() (valid int64, torn bool, err error) {
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("workerpool: read journal: %w", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A crash in the middle of a write leaves a torn last line
+			return valid, len(line) > 0, nil
+		}
+		if err != nil {
+			return 0, false, fmt.Errorf("workerpool: read journal: %w", err)
+		}
+		valid += int64(len(line))
+
+		var rec journalRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue // Garbage left by an older crash
+		}
+		switch rec.Op {
+		case opEnqueue:
+			j.pending[rec.ID] = rec
+		case opAck:
+			delete(j.pending, rec.ID)
+		}
+		j.nextID = max(j.nextID, rec.ID)
+	}
+}
+
+// Pending returns the number of jobs that have not been acknowledged
+func (j *Journal) Pending() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.pending)
+}
+
+// enqueue durably records a job and returns its journal ID
+func (j *Journal) enqueue(job json.RawMessage, priority int, key, tenant, circuit string) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.nextID++
+	rec := journalRecord{Op: opEnqueue, ID: j.nextID, Job: job, Priority: priority, Key: key, Tenant: tenant, Circuit: circuit}
+	if err := j.writeLocked(rec); err != nil {
+		return 0, err
+	}
+	j.pending[rec.ID] = rec
+	return rec.ID, nil
+}
+
+// ack durably records that a job has a final result
+func (j *Journal) ack(id uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.pending[id]; !ok {
+		return nil
+	}
+	if err := j.writeLocked(journalRecord{Op: opAck, ID: id}); err != nil {
+		return err
+	}
+	delete(j.pending, id)
+
+	j.acks++
+	if j.CompactEvery > 0 && j.acks >= j.CompactEvery {
+		return j.compactLocked()
+	}
+	return nil
+}
+
+// unacked returns the pending records in the order they were enqueued
+func (j *Journal) unacked() []journalRecord {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records := make([]journalRecord, 0, len(j.pending))
+	for _, id := range slices.Sorted(maps.Keys(j.pending)) {
+		records = append(records, j.pending[id])
+	}
+	return records
+}
+
+// writeLocked appends one record and fsyncs the file. The caller must hold mu.
+func (j *Journal) writeLocked(rec journalRecord) error {
+	if j.file == nil {
+		return errors.New("workerpool: journal is closed")
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("workerpool: encode journal record: %w", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("workerpool: write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("workerpool: sync journal: %w", err)
+	}
+	return nil
+}
+
+// Compact rewrites the journal so it only contains unacknowledged jobs
+func (j *Journal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.compactLocked()
+}
+
+// compactLocked writes the pending records to a temporary file and renames
+// it over the journal, so a crash leaves either the old or the new file.
+// The caller must hold mu.
+func (j *Journal) compactLocked() error {
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".compact-*")
+	if err != nil {
+		return fmt.Errorf("workerpool: compact journal: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	w := bufio.NewWriter(tmp)
+	for _, id := range slices.Sorted(maps.Keys(j.pending)) {
+		line, err := json.Marshal(j.pending[id])
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("workerpool: compact journal: %w", err)
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := errors.Join(w.Flush(), tmp.Sync(), tmp.Close()); err != nil {
+		return fmt.Errorf("workerpool: compact journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("workerpool: compact journal: %w", err)
+	}
+	syncDir(filepath.Dir(j.path))
+
+	// Continue appending to the new file
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("workerpool: reopen journal: %w", err)
+	}
+	j.file.Close()
+	j.file = file
+	j.acks = 0
+	return nil
+}
+
+// syncDir fsyncs a directory so a rename inside it is durable
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Close closes the journal file. Unacknowledged jobs stay in the file and
+// are replayed the next time it is opened.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// WithJournal makes the pool's queue durable: jobs are written to journal
+// before they are queued, and jobs left unacknowledged by a previous run are
+// queued again when the pool starts (see Recovered). The pool does not
+// close the journal.
+func WithJournal(journal *Journal) Option {
+	return func(c *config) {
+		c.journal = journal
+	}
+}
+
+// replay queues the jobs a previous run left unacknowledged
+func (p *Pool[J, R]) replay() {
+	for _, rec := range p.journal.unacked() {
+		var job J
+		if err := json.Unmarshal(rec.Job, &job); err != nil {
+			// The job can never run; drop it instead of replaying it forever
+			p.publish(Event{Type: EventJournalError, Reason: fmt.Sprintf("decode job %d: %v", rec.ID, err)})
+			p.journal.ack(rec.ID)
+			continue
+		}
+
+		t := &task[J, R]{
+			job:       job,
+			future:    newFuture[R](p.nextID.Add(1)),
+			priority:  rec.Priority,
+			timeout:   p.jobTimeout,
+			journalID: rec.ID,
+			key:       rec.Key,
+			tenant:    rec.Tenant,
+			circuit:   rec.Circuit,
+		}
+		p.enqueueLocked(t, -1)
+		p.recovered = append(p.recovered, t.future)
+	}
+}
+
+// Recovered returns the futures of jobs replayed from the journal when the
+// pool was created
+func (p *Pool[J, R]) Recovered() []*Future[R] {
+	return p.recovered
+}
+
+// journalEnqueue writes a submitted job to the journal, if there is one
+func (p *Pool[J, R]) journalEnqueue(t *task[J, R]) error {
+	if p.journal == nil {
+		return nil
+	}
+	raw, err := json.Marshal(t.job)
+	if err != nil {
+		return fmt.Errorf("workerpool: encode job: %w", err)
+	}
+	t.journalID, err = p.journal.enqueue(raw, t.priority, t.key, t.tenant, t.circuit)
+	return err
+}
+
+// journalAck marks a journaled job as done
+func (p *Pool[J, R]) journalAck(t *task[J, R]) {
+	if p.journal == nil || t.journalID == 0 {
+		return
+	}
+	if err := p.journal.ack(t.journalID); err != nil {
+		p.publish(Event{Type: EventJournalError, Reason: err.Error()})
+	}
+}
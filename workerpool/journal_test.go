@@ -0,0 +1,141 @@
+package workerpool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJournalRepairsTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.enqueue(json.RawMessage(`1`), 0, "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+
+	// A crash in the middle of the next write
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"enq`)
+	f.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range []string{`42`, `43`} {
+		if _, err := j.enqueue(json.RawMessage(job), 0, "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	var jobs []string
+	for _, rec := range j.unacked() {
+		jobs = append(jobs, string(rec.Job))
+	}
+	if want := []string{`1`, `42`, `43`}; !slices.Equal(jobs, want) {
+		t.Fatalf("replayed %v, want %v", jobs, want)
+	}
+}
+
+// journalHelperEnv names the journal of the helper process
+const journalHelperEnv = "WORKERPOOL_JOURNAL_HELPER"
+
+// TestJournalHelperProcess is the process killed by TestJournalSurvivesKill.
+// It submits jobs that never finish, reports it, and waits to be killed.
+func TestJournalHelperProcess(t *testing.T) {
+	path := os.Getenv(journalHelperEnv)
+	if path == "" {
+		t.Skip("helper process for TestJournalSurvivesKill")
+	}
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := New(2, func(ctx context.Context, job int) (int, error) {
+		select {} // Still running when the process is killed
+	}, WithQueueSize(10), WithJournal(j))
+	for job := range 10 {
+		if _, err := pool.Submit(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Stdout.WriteString("submitted\n")
+	select {}
+}
+
+func TestJournalSurvivesKill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a subprocess")
+	}
+	path := filepath.Join(t.TempDir(), "jobs.journal")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestJournalHelperProcess$")
+	cmd.Env = append(os.Environ(), journalHelperEnv+"="+path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Kill the process once every job is journaled, two of them mid-run
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && scanner.Text() != "submitted" {
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if got := j.Pending(); got != 10 {
+		t.Fatalf("pending after kill = %d, want 10", got)
+	}
+
+	var mu sync.Mutex
+	var ran []int
+	pool := New(2, func(ctx context.Context, job int) (int, error) {
+		mu.Lock()
+		ran = append(ran, job)
+		mu.Unlock()
+		return job, nil
+	}, WithQueueSize(10), WithJournal(j))
+	for _, future := range pool.Recovered() {
+		select {
+		case <-future.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("replayed job did not finish")
+		}
+	}
+	pool.Close()
+
+	slices.Sort(ran)
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(ran, want) {
+		t.Fatalf("replayed jobs %v, want %v", ran, want)
+	}
+	if got := j.Pending(); got != 0 {
+		t.Fatalf("pending after replay = %d, want 0", got)
+	}
+}
@@ -0,0 +1,136 @@
+package workerpool
+
+import "hash/maphash"
+
+// WithKey makes the job run serially with every other job of the same key,
+// in submission order, while jobs with different keys run in parallel.
+//
+// Only the oldest job of a key is queued for a worker; later ones wait in
+// the key's own lane until it finishes (including its retries, and a handler
+// that overran its timeout and was abandoned, until it returns). A hot key
+// therefore never holds up other keys: its backlog waits off to the side
+// instead of in front of them, and is bounded by the lane size (see
+// WithKeyLaneSize) rather than by the shared queue. In work-stealing mode
+// the key also picks the deque its jobs start on, so they tend to stay on
+// one worker.
+func WithKey(key string) SubmitOption {
+	return func(c *submitConfig) {
+		c.key = key
+	}
+}
+
+// WithKeyLaneSize sets how many jobs of one key may wait behind its active
+// job before the overflow policy applies to that key (default: the queue
+// size). Each key has its own limit, so a full lane only holds up
+// submitters of that key.
+func WithKeyLaneSize(size int) Option {
+	return func(c *config) {
+		c.keyLaneSize = size
+	}
+}
+
+// keySeed hashes keys to work-stealing deques
+var keySeed = maphash.MakeSeed()
+
+// enqueueLocked queues t for a worker, or parks it in its key's lane when
+// another job of the same key is active. worker is the submitting worker
+// (-1 from outside the pool). It reports whether t was queued, i.e. whether
+// a worker should be woken. The caller must hold mu.
+func (p *Pool[J, R]) enqueueLocked(t *task[J, R], worker int) bool {
+	if t.key != "" {
+		if lane, active := p.keys[t.key]; active {
+			p.keys[t.key] = append(lane, t)
+			p.keyWaiting++
+			return false
+		}
+		p.keys[t.key] = nil
+	}
+	p.pushLocked(t, worker)
+	return true
+}
+
+// pushLocked puts t in the queue. The caller must hold mu.
+func (p *Pool[J, R]) pushLocked(t *task[J, R], worker int) {
+	t.enqueuedAt = p.sinceCreated()
+	switch {
+	case p.steal == nil:
+		p.queue.push(t)
+	case t.key != "":
+		p.steal.pushLocal(int(maphash.String(keySeed, t.key)%uint64(len(p.steal.deques))), t)
+	default:
+		// A job submitted by a handler stays with the worker that made it
+		p.steal.pushLocal(worker, t)
+	}
+}
+
+// releaseKeyLocked ends the active job of key and queues the next job
+// waiting in its lane, which becomes the active one. It reports whether a
+// job was queued. The caller must hold mu.
+func (p *Pool[J, R]) releaseKeyLocked(key string) bool {
+	lane := p.keys[key]
+	if len(lane) == 0 {
+		delete(p.keys, key)
+		return false
+	}
+
+	next := lane[0]
+	lane[0] = nil
+	p.keys[key] = lane[1:]
+	p.keyWaiting--
+	p.pushLocked(next, -1)
+	p.signalSpaceLocked() // The lane has room again
+	return true
+}
+
+// skipLaneLocked resolves the jobs waiting in key's lane as never started
+// and ends the key. The caller must hold mu.
+func (p *Pool[J, R]) skipLaneLocked(key string) {
+	for _, t := range p.keys[key] {
+		p.skip(t)
+	}
+	p.keyWaiting -= len(p.keys[key])
+	delete(p.keys, key)
+}
+
+// releaseKey is releaseKeyLocked for a finished keyed task
+func (p *Pool[J, R]) releaseKey(t *task[J, R]) {
+	if t.key == "" {
+		return
+	}
+	p.mu.Lock()
+	queued := p.releaseKeyLocked(t.key)
+	p.mu.Unlock()
+
+	if queued {
+		p.wake()
+	}
+}
+
+// queuedLocked returns the number of jobs waiting for a worker, including
+// those waiting in key lanes. The caller must hold mu.
+func (p *Pool[J, R]) queuedLocked() int {
+	return p.queue.len() + p.keyWaiting
+}
+
+// laneFullLocked reports whether t would wait in its key's lane and that
+// lane is full. The caller must hold mu.
+func (p *Pool[J, R]) laneFullLocked(t *task[J, R]) (full, parked bool) {
+	if t.key == "" {
+		return false, false
+	}
+	lane, active := p.keys[t.key]
+	if !active {
+		return false, false
+	}
+	return len(lane) >= p.laneSize, true
+}
+
+// fullLocked reports whether there is no room for t: a job that would wait
+// behind an active job of its key counts against the key's lane, any other
+// against the shared queue. The caller must hold mu.
+func (p *Pool[J, R]) fullLocked(t *task[J, R]) bool {
+	if full, parked := p.laneFullLocked(t); parked {
+		return full
+	}
+	return p.queue.len() >= p.queueSize
+}
@@ -0,0 +1,217 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyTracker records the jobs of each key as they start and counts the
+// times a job started while another of its key was still running
+type keyTracker struct {
+	mu       sync.Mutex
+	active   map[string]int
+	order    map[string][]string
+	overlaps int
+}
+
+func newKeyTracker() *keyTracker {
+	return &keyTracker{active: make(map[string]int), order: make(map[string][]string)}
+}
+
+func (k *keyTracker) start(key, job string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.active[key]++; k.active[key] > 1 {
+		k.overlaps++
+	}
+	k.order[key] = append(k.order[key], job)
+}
+
+func (k *keyTracker) end(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.active[key]--
+}
+
+// waitAll waits for every future, failing the test if one takes too long
+func waitAll[R any](t *testing.T, futures ...*Future[R]) {
+	t.Helper()
+	for _, future := range futures {
+		select {
+		case <-future.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("job did not finish")
+		}
+	}
+}
+
+func TestSameKeyJobsRunSeriallyInOrder(t *testing.T) {
+	type job struct{ key, name string }
+	tracker := newKeyTracker()
+	pool := New(4, func(ctx context.Context, j job) (struct{}, error) {
+		tracker.start(j.key, j.name)
+		time.Sleep(100 * time.Microsecond) // Give a second job of the key a chance to overlap
+		tracker.end(j.key)
+		return struct{}{}, nil
+	}, WithQueueSize(64))
+	defer pool.Close()
+
+	keys := []string{"a", "b", "c"}
+	want := make(map[string][]string)
+	var futures []*Future[struct{}]
+	for i := range 20 {
+		for _, key := range keys {
+			j := job{key, fmt.Sprintf("%s%d", key, i)}
+			want[key] = append(want[key], j.name)
+			future, err := pool.Submit(context.Background(), j, WithKey(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			futures = append(futures, future)
+		}
+	}
+	waitAll(t, futures...)
+
+	if tracker.overlaps != 0 {
+		t.Errorf("same-key jobs overlapped %d times", tracker.overlaps)
+	}
+	for _, key := range keys {
+		if got := tracker.order[key]; !slices.Equal(got, want[key]) {
+			t.Errorf("key %s ran %v, want %v", key, got, want[key])
+		}
+	}
+}
+
+func TestKeyHeldByOverrunHandler(t *testing.T) {
+	for _, retry := range []bool{false, true} {
+		t.Run(fmt.Sprintf("retry=%v", retry), func(t *testing.T) {
+			clock := NewFakeClock(epoch)
+			tracker := newKeyTracker()
+			release := make(chan struct{})
+			started := make(chan string, 3)
+			var attempts sync.Map
+			opts := []Option{WithClock(clock)}
+			if retry {
+				opts = append(opts, WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Second}))
+			}
+			pool := New(2, func(ctx context.Context, job string) (string, error) {
+				n, _ := attempts.LoadOrStore(job, new(int))
+				*n.(*int)++
+				name := fmt.Sprintf("%s#%d", job, *n.(*int))
+				tracker.start("acct", name)
+				defer tracker.end("acct")
+				started <- name
+				if name == "first#1" {
+					<-release // Ignores its context, so it overruns
+					return "", errors.New("too late")
+				}
+				return job, nil
+			}, opts...)
+			defer pool.Close()
+
+			first, err := pool.Submit(context.Background(), "first", WithKey("acct"), WithTimeout(time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := pool.Submit(context.Background(), "second", WithKey("acct"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-started
+
+			// Time out the first job and let the worker give up on it
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+			clock.BlockUntil(1)
+			clock.Advance(overrunGrace)
+			if retry {
+				clock.BlockUntil(1)
+				clock.Advance(time.Second)
+			} else {
+				waitAll(t, first)
+			}
+
+			// Nothing else of the key may start while the handler runs on
+			select {
+			case name := <-started:
+				t.Errorf("%s started while the abandoned handler was still running", name)
+			case <-time.After(100 * time.Millisecond):
+			}
+			close(release)
+			waitAll(t, first, second)
+
+			want := []string{"first#1", "second#1"}
+			if retry {
+				want = []string{"first#1", "first#2", "second#1"}
+			}
+			if got := tracker.order["acct"]; !slices.Equal(got, want) || tracker.overlaps != 0 {
+				t.Fatalf("ran %v with %d overlaps, want %v with none", got, tracker.overlaps, want)
+			}
+		})
+	}
+}
+
+func TestHotKeyDoesNotBlockOtherKeys(t *testing.T) {
+	release := make(chan struct{})
+	pool := New(2, func(ctx context.Context, key string) (string, error) {
+		if key == "hot" {
+			<-release
+		}
+		return key, nil
+	}, WithQueueSize(4), WithKeyLaneSize(4))
+	defer pool.Close()
+	defer close(release)
+
+	// The hot key's active job holds a worker and its lane is full, so the
+	// next hot submitter blocks
+	for range 5 {
+		if _, err := pool.Submit(context.Background(), "hot", WithKey("hot")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		pool.Submit(context.Background(), "hot", WithKey("hot"))
+	}()
+
+	cold, err := pool.Submit(context.Background(), "cold", WithKey("cold"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitAll(t, cold)
+	select {
+	case <-blocked:
+		t.Fatal("submit to the full hot lane did not block")
+	default:
+	}
+}
+
+func TestKeyLaneSize(t *testing.T) {
+	release := make(chan struct{})
+	pool := New(2, func(ctx context.Context, job int) (int, error) {
+		<-release
+		return job, nil
+	}, WithQueueSize(2), WithKeyLaneSize(2), WithOverflow(OverflowReject))
+	defer pool.Close()
+	defer close(release)
+
+	// One active job and two waiting fill the lane of "a"
+	for job := range 3 {
+		if _, err := pool.Submit(context.Background(), job, WithKey("a")); err != nil {
+			t.Fatalf("job %d: %v", job, err)
+		}
+	}
+	if _, err := pool.Submit(context.Background(), 3, WithKey("a")); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("submit to a full lane: err = %v, want %v", err, ErrQueueFull)
+	}
+	// Other keys still get in
+	if _, err := pool.Submit(context.Background(), 4, WithKey("b")); err != nil {
+		t.Fatalf("submit to another key: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package workerpool
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the job duration histogram
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Bucket is one cumulative histogram bucket
+type Bucket struct {
+	UpperBound time.Duration // jobs that took at most this long
+	Count      uint64
+}
+
+// Histogram is a snapshot of the job duration distribution
+type Histogram struct {
+	Buckets []Bucket // cumulative counts, one per bound in ascending order
+	Count   uint64   // total observations (the implicit +Inf bucket)
+	Sum     time.Duration
+}
+
+// metrics holds the counters and histograms behind Stats and /metrics
+type metrics struct {
+	submitted atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+
+	mu      sync.Mutex
+	busy    map[int]time.Duration // worker ID -> time spent in handlers
+	buckets []uint64              // non-cumulative counts per latency bucket
+	count   uint64
+	sum     time.Duration
+}
+
+func newMetrics() metrics {
+	return metrics{
+		busy:    make(map[int]time.Duration),
+		buckets: make([]uint64, len(latencyBuckets)),
+	}
+}
+
+// observe records one handler call made by a worker
+func (m *metrics) observe(workerID int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.busy[workerID] += d
+	m.count++
+	m.sum += d
+	if i, _ := slices.BinarySearch(latencyBuckets, d); i < len(latencyBuckets) {
+		m.buckets[i]++
+	}
+}
+
+// finished counts a job that reached its final result
+func (m *metrics) finished(err error) {
+	if err != nil {
+		m.failed.Add(1)
+	} else {
+		m.succeeded.Add(1)
+	}
+}
+
+// workerBusy returns a copy of the per-worker busy time
+func (m *metrics) workerBusy() map[int]time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return maps.Clone(m.busy)
+}
+
+// latency returns a cumulative snapshot of the duration histogram
+func (m *metrics) latency() Histogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := Histogram{
+		Buckets: make([]Bucket, len(latencyBuckets)),
+		Count:   m.count,
+		Sum:     m.sum,
+	}
+	var cumulative uint64
+	for i, bound := range latencyBuckets {
+		cumulative += m.buckets[i]
+		h.Buckets[i] = Bucket{UpperBound: bound, Count: cumulative}
+	}
+	return h
+}
+
+// WritePrometheus writes the pool's metrics in the Prometheus text
+// exposition format. Every series carries a pool label set by WithName.
+func (p *Pool[J, R]) WritePrometheus(w io.Writer) error {
+	s := p.Stats()
+	bw := bufio.NewWriter(w)
+	label := fmt.Sprintf("pool=%q", p.name)
+
+	// Counters and gauges that are a single number
+	single := []struct {
+		name, kind, help string
+		value            int
+	}{
+		{"workerpool_jobs_submitted_total", "counter", "Jobs submitted to the pool.", s.Submitted},
+		{"workerpool_jobs_completed_total", "counter", "Jobs that finished without an error.", s.Completed},
+		{"workerpool_jobs_failed_total", "counter", "Jobs that failed after their last attempt.", s.Failed},
+		{"workerpool_jobs_retried_total", "counter", "Retries scheduled for failed jobs.", s.Retried},
+		{"workerpool_jobs_rejected_total", "counter", "Submits refused because the queue was full.", s.Rejected},
+		{"workerpool_jobs_dropped_total", "counter", "Jobs dropped because the queue was full.", s.Dropped},
+		{"workerpool_jobs_caller_runs_total", "counter", "Jobs run on the submitting goroutine.", s.CallerRuns},
+		{"workerpool_jobs_deduplicated_total", "counter", "Submits that joined a job with the same idempotency key.", s.Deduplicated},
+		{"workerpool_submits_blocked_total", "counter", "Submits that waited for room in the queue.", s.Blocked},
+		{"workerpool_workers_replaced_total", "counter", "Workers replaced after a job overran its timeout.", s.Replaced},
+		{"workerpool_queue_depth", "gauge", "Jobs waiting for a worker.", s.QueueDepth},
+		{"workerpool_workers", "gauge", "Live worker goroutines.", s.Workers},
+		{"workerpool_workers_busy", "gauge", "Workers currently running a job.", s.Busy},
+	}
+	for _, m := range single {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		fmt.Fprintf(bw, "%s{%s} %d\n", m.name, label, m.value)
+	}
+
+	// Busy time per worker, in worker ID order
+	fmt.Fprintf(bw, "# HELP workerpool_worker_busy_seconds_total Time each worker spent running jobs.\n")
+	fmt.Fprintf(bw, "# TYPE workerpool_worker_busy_seconds_total counter\n")
+	for _, id := range slices.Sorted(maps.Keys(s.WorkerBusy)) {
+		fmt.Fprintf(bw, "workerpool_worker_busy_seconds_total{%s,worker=\"%d\"} %g\n",
+			label, id, s.WorkerBusy[id].Seconds())
+	}
+
+	// Per-tenant queues, in tenant order
+	if s.Tenants != nil {
+		tenants := slices.Sorted(maps.Keys(s.Tenants))
+		tenantSeries := []struct {
+			name, kind, help string
+			value            func(TenantStats) float64
+		}{
+			{"workerpool_tenant_queue_depth", "gauge", "Jobs of the tenant waiting for a worker.",
+				func(t TenantStats) float64 { return float64(t.Queued) }},
+			{"workerpool_tenant_jobs_dispatched_total", "counter", "Jobs of the tenant handed to a worker.",
+				func(t TenantStats) float64 { return float64(t.Dispatched) }},
+			{"workerpool_tenant_wait_seconds_total", "counter", "Time dispatched jobs of the tenant spent queued.",
+				func(t TenantStats) float64 { return t.Wait.Seconds() }},
+			{"workerpool_tenant_oldest_wait_seconds", "gauge", "Age of the tenant's oldest waiting job.",
+				func(t TenantStats) float64 { return t.OldestWait.Seconds() }},
+		}
+		for _, m := range tenantSeries {
+			fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+			for _, tenant := range tenants {
+				fmt.Fprintf(bw, "%s{%s,tenant=%q} %g\n", m.name, label, tenant, m.value(s.Tenants[tenant]))
+			}
+		}
+	}
+
+	// Circuit breakers, in circuit order
+	if s.Circuits != nil {
+		circuits := slices.Sorted(maps.Keys(s.Circuits))
+		circuitSeries := []struct {
+			name, kind, help string
+			value            func(CircuitStats) int
+		}{
+			{"workerpool_circuit_state", "gauge", "State of the circuit: 0 closed, 1 open, 2 half-open.",
+				func(c CircuitStats) int { return int(c.State) }},
+			{"workerpool_circuit_rejected_total", "counter", "Jobs rejected by the circuit without calling the handler.",
+				func(c CircuitStats) int { return c.Rejected }},
+			{"workerpool_circuit_opened_total", "counter", "Times the circuit opened.",
+				func(c CircuitStats) int { return c.Opened }},
+		}
+		for _, m := range circuitSeries {
+			fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+			for _, name := range circuits {
+				fmt.Fprintf(bw, "%s{%s,circuit=%q} %d\n", m.name, label, name, m.value(s.Circuits[name]))
+			}
+		}
+	}
+
+	// Job duration histogram
+	fmt.Fprintf(bw, "# HELP workerpool_job_duration_seconds Time spent in the job handler.\n")
+	fmt.Fprintf(bw, "# TYPE workerpool_job_duration_seconds histogram\n")
+	for _, b := range s.Latency.Buckets {
+		fmt.Fprintf(bw, "workerpool_job_duration_seconds_bucket{%s,le=\"%g\"} %d\n",
+			label, b.UpperBound.Seconds(), b.Count)
+	}
+	fmt.Fprintf(bw, "workerpool_job_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, s.Latency.Count)
+	fmt.Fprintf(bw, "workerpool_job_duration_seconds_sum{%s} %g\n", label, s.Latency.Sum.Seconds())
+	fmt.Fprintf(bw, "workerpool_job_duration_seconds_count{%s} %d\n", label, s.Latency.Count)
+
+	return bw.Flush()
+}
+
+// MetricsHandler returns an http.Handler that serves the pool's metrics in
+// the Prometheus text format, e.g. mounted at /metrics
+func (p *Pool[J, R]) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed. Please use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		p.WritePrometheus(w)
+	})
+}
@@ -0,0 +1,112 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultDrainTimeout is how long in-flight jobs may keep running after the
+// pool's parent context is cancelled
+const DefaultDrainTimeout = 5 * time.Second
+
+// Option configures a Pool
+type Option func(*config)
+
+// config holds the settings collected from Options
+type config struct {
+	name         string
+	queueSize    int
+	keyLaneSize  int
+	ctx          context.Context
+	drainTimeout time.Duration
+	autoscale    *Autoscale
+	onEvent      func(Event)
+	aging        time.Duration
+	retry        *RetryPolicy
+	deadLetters  int
+	jobTimeout   time.Duration
+	overflow     OverflowPolicy
+	journal      *Journal
+	clock        Clock
+	stealing     bool
+	dedupTTL     time.Duration
+	dedupEntries int
+	tenants      map[string]int
+	onResolve    func(err error)
+	breaker      *Breaker
+
+	progressInterval time.Duration
+	onProgress       func(Progress)
+}
+
+// WithName sets the name used for the pool label in exported metrics
+// (default "default")
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithQueueSize sets how many jobs may wait for a worker before the
+// overflow policy applies (default: number of workers)
+func WithQueueSize(size int) Option {
+	return func(c *config) {
+		c.queueSize = size
+	}
+}
+
+// WithContext ties the pool to ctx: once ctx is cancelled the pool stops
+// dispatching queued jobs and shuts down gracefully
+func WithContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// WithDrainTimeout sets how long in-flight jobs may run after the parent
+// context is cancelled before their own context is cancelled
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.drainTimeout = d
+	}
+}
+
+// WithAutoscale lets the pool add and retire workers within the given bounds
+func WithAutoscale(a Autoscale) Option {
+	return func(c *config) {
+		c.autoscale = &a
+	}
+}
+
+// WithEvents registers a function that receives pool events such as
+// scaling decisions. It is called synchronously, so it should return quickly.
+func WithEvents(fn func(Event)) Option {
+	return func(c *config) {
+		c.onEvent = fn
+	}
+}
+
+// WithAging protects low-priority jobs from starvation: a queued job gains
+// one priority level for every interval it has been waiting
+func WithAging(interval time.Duration) Option {
+	return func(c *config) {
+		c.aging = interval
+	}
+}
+
+// WithJobTimeout gives every job attempt a deadline through its context.
+// A handler that ignores the deadline is abandoned and its worker replaced.
+func WithJobTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.jobTimeout = d
+	}
+}
+
+// WithClock replaces the wall clock behind every timeout, backoff, schedule
+// and measurement of the pool, e.g. with a FakeClock in tests. Handlers can
+// sleep on it with Sleep.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
@@ -0,0 +1,94 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// overrunGrace is how long a handler may take to return after its deadline
+// before the worker gives up on it
+const overrunGrace = 100 * time.Millisecond
+
+// PanicError is the result of a job whose handler panicked
+type PanicError struct {
+	Value any    // value passed to panic
+	Stack []byte // stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: job panicked: %v", e.Value)
+}
+
+// outcome is what a handler call produced
+type outcome[R any] struct {
+	value R
+	err   error
+}
+
+// safeCall runs the handler and turns a panic into a PanicError
+func (p *Pool[J, R]) safeCall(ctx context.Context, job J) (out outcome[R]) {
+	defer func() {
+		if v := recover(); v != nil {
+			out = outcome[R]{err: &PanicError{Value: v, Stack: debug.Stack()}}
+		}
+	}()
+
+	value, err := p.handler(ctx, job)
+	return outcome[R]{value: value, err: err}
+}
+
+// call runs one attempt of a task. Without a timeout the handler runs on
+// the worker goroutine. With one, it runs on its own goroutine so the worker
+// can give up once the deadline (plus a short grace period) has passed; the
+// overran result tells the caller to replace the worker, and t.abandoned is
+// closed once the abandoned handler returns.
+func (p *Pool[J, R]) call(ctx context.Context, t *task[J, R]) (out outcome[R], overran bool) {
+	if t.timeout <= 0 {
+		return p.safeCall(ctx, t.job), false
+	}
+
+	jobCtx, cancel := withTimeout(ctx, p.clock, t.timeout)
+	defer cancel()
+
+	// Buffered so an abandoned handler can still finish without blocking
+	done := make(chan outcome[R], 1)
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		done <- p.safeCall(jobCtx, t.job)
+	}()
+
+	select {
+	case out := <-done:
+		return out, false
+	case <-jobCtx.Done():
+	}
+
+	// Give a handler that honours its context a moment to return
+	grace := p.clock.NewTimer(overrunGrace)
+	defer grace.Stop()
+	select {
+	case out := <-done:
+		return out, false
+	case <-grace.C():
+		t.abandoned = returned
+		return outcome[R]{err: jobCtx.Err()}, true
+	}
+}
+
+// replace starts a new worker in place of one whose handler overran
+func (p *Pool[J, R]) replace(id int) {
+	p.workersMu.Lock()
+	p.startWorkerLocked()
+	p.replaced++
+	workers := p.workers - 1 // the old worker is about to exit
+	p.workersMu.Unlock()
+
+	p.publish(Event{
+		Type:    EventWorkerReplaced,
+		Workers: workers,
+		Reason:  fmt.Sprintf("worker %d abandoned a job that overran its timeout", id),
+	})
+}
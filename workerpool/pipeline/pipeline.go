@@ -0,0 +1,122 @@
+// Package pipeline chains workerpool stages into a fan-out/fan-in pipeline.
+// Every stage runs on its own pool with its own worker count and bounded
+// buffer, and the first error cancels every stage.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// Pipeline holds the context and error shared by a chain of stages
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+// New creates a pipeline whose stages stop when ctx is cancelled
+func New(ctx context.Context) *Pipeline {
+	child, cancel := context.WithCancel(ctx)
+	return &Pipeline{parent: ctx, ctx: child, cancel: cancel}
+}
+
+// Context returns the context shared by every stage. It is cancelled after
+// the first error.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// fail records the first error and cancels every stage
+func (p *Pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// Wait blocks until every stage has finished and returns the first error.
+// The output of the last stage must be consumed (or use Sink), otherwise
+// Wait never returns.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	if p.err == nil {
+		return context.Cause(p.parent)
+	}
+	return p.err
+}
+
+// Source feeds items into the pipeline
+func Source[T any](p *Pipeline, items iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	p.wg.Go(func() {
+		defer close(out)
+		for item := range items {
+			select {
+			case out <- item:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Stage processes every item read from in with fn on its own pool of
+// workers. Up to buffer items wait for a worker and up to buffer results
+// wait for the next stage. The returned channel is closed once in is closed
+// and every item has been processed, or when the pipeline is cancelled.
+func Stage[I, O any](p *Pipeline, name string, in <-chan I, workers, buffer int, fn workerpool.Handler[I, O]) <-chan O {
+	pool := workerpool.New(workers, fn,
+		workerpool.WithName(name),
+		workerpool.WithQueueSize(buffer),
+		workerpool.WithContext(p.ctx),
+		workerpool.WithDrainTimeout(0), // Cancel in-flight jobs as soon as the pipeline fails
+	)
+
+	out := make(chan O, buffer)
+	p.wg.Go(func() {
+		defer pool.Close()
+		defer close(out)
+
+		// Keep reading until the results channel closes so no waiter leaks
+		for result := range pool.Unordered(p.ctx, in) {
+			if result.Err != nil {
+				if p.ctx.Err() != nil {
+					// Cancelled from outside: report why, not the side effects
+					p.fail(context.Cause(p.ctx))
+					continue
+				}
+				p.fail(fmt.Errorf("pipeline: stage %q: %w", name, result.Err))
+				continue
+			}
+			select {
+			case out <- result.Value:
+			case <-p.ctx.Done():
+			}
+		}
+	})
+	return out
+}
+
+// Sink consumes every item read from in with fn, ending the pipeline
+func Sink[T any](p *Pipeline, name string, in <-chan T, workers int, fn func(ctx context.Context, item T) error) {
+	done := Stage(p, name, in, workers, workers, func(ctx context.Context, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	})
+
+	// Drain the empty results so the stage never blocks
+	p.wg.Go(func() {
+		for range done {
+		}
+	})
+}
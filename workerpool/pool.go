@@ -0,0 +1,692 @@
+// Package workerpool provides a reusable, generic worker pool.
+//
+// Jobs of type J are submitted to the pool and processed concurrently by a
+// fixed number of worker goroutines. Each submission returns a Future that
+// resolves to the handler's result of type R (or its error).
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handler processes a single job and returns its result
+type Handler[J, R any] func(ctx context.Context, job J) (R, error)
+
+var (
+	// ErrPoolClosed is returned when submitting to a pool that has been closed
+	ErrPoolClosed = errors.New("workerpool: pool is closed")
+
+	// ErrNotStarted is the result of jobs still queued when the pool shut down
+	ErrNotStarted = errors.New("workerpool: job was not started before shutdown")
+
+	// ErrNoDeadLetter is returned when requeueing a dead letter that does not exist
+	ErrNoDeadLetter = errors.New("workerpool: no such dead letter")
+)
+
+// Summary reports what happened to the jobs of a pool that has stopped
+type Summary struct {
+	Completed  int // jobs whose handler returned before the drain deadline
+	Cancelled  int // in-flight jobs whose context was cancelled at the drain deadline
+	NotStarted int // queued jobs that were never handed to a worker
+}
+
+// task couples a job with the future that receives its result
+type task[J, R any] struct {
+	job        J
+	future     *Future[R]
+	priority   int
+	timeout    time.Duration // per-attempt deadline, 0 for none
+	enqueuedAt time.Duration // time since the pool was created
+	journalID  uint64        // ID in the pool's journal, 0 when not journaled
+	attempts   int           // handler calls made so far
+	lastErr    error         // error from the most recent attempt
+	key        string        // serializes jobs with the same key, "" for none
+	tenant     string        // fair-queuing tenant, "" for DefaultTenant
+	circuit    string        // circuit breaker, "" for DefaultCircuit
+	abandoned  chan struct{} // closed when the handler of an overrun attempt returns
+}
+
+// Pool runs jobs concurrently on a set of worker goroutines
+type Pool[J, R any] struct {
+	handler Handler[J, R]
+	wg      sync.WaitGroup
+	created time.Time
+
+	// ctx is passed to handlers; cancel aborts in-flight jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu guards the queue, the closed flag and the bookkeeping that decides
+	// when a closed pool has no work left. closed and inflight are atomic so
+	// work-stealing workers can update them without taking mu; they are
+	// still only set to their final values under mu.
+	mu        sync.Mutex
+	queue     queue[J, R]
+	steal     *stealingQueue[J, R] // same as queue in work-stealing mode, else nil
+	fair      *fairQueue[J, R]     // same as queue with tenants, else nil
+	queueSize int
+	laneSize  int // limit of each key's lane
+	overflow  OverflowPolicy
+	closed    atomic.Bool
+	done      bool         // finish has run; late retries resolve instead of requeueing
+	inflight  atomic.Int64 // tasks taken by a worker and not yet resolved or rescheduled
+	retries   map[*task[J, R]]Timer
+	nextID    atomic.Uint64
+
+	// keys holds a lane for every key with an active job: the jobs of that
+	// key waiting for it to finish, keyWaiting of them in total
+	keys       map[string][]*task[J, R]
+	keyWaiting int
+
+	// dedup answers duplicate submits of the same idempotency key
+	dedup        *dedupTable[R]
+	deduplicated atomic.Int64
+
+	retry      *RetryPolicy // nil when failed jobs are not retried
+	breakers   *breakers    // nil without WithBreaker
+	dead       *DeadLetterQueue[J]
+	journal    *Journal
+	recovered  []*Future[R]
+	jobTimeout time.Duration
+
+	// ready wakes idle workers after a push; space wakes blocked submitters
+	// after a pop (it is closed and replaced each time)
+	ready        chan struct{}
+	space        chan struct{}
+	spaceWaiters atomic.Int64
+
+	// closing is closed when the pool stops accepting jobs, drained once it
+	// is closed and has no queued, running or retrying jobs left, and
+	// stopping when it stops dispatching jobs altogether
+	closing      chan struct{}
+	drained      chan struct{}
+	stopping     chan struct{}
+	closeOnce    sync.Once
+	drainOnce    sync.Once
+	stopOnce     sync.Once
+	finishOnce   sync.Once
+	finished     chan struct{}
+	completed    atomic.Int64
+	cancelled    atomic.Int64
+	notStarted   atomic.Int64
+	drainTimeout time.Duration
+
+	// workersMu guards the live worker count and scaling counters
+	workersMu  sync.Mutex
+	workers    int
+	nextWorker int
+	scaleUps   int
+	scaleDowns int
+	replaced   int
+	scale      *Autoscale // nil when the worker count is fixed
+
+	busy       atomic.Int64
+	rejected   atomic.Int64
+	dropped    atomic.Int64
+	callerRuns atomic.Int64
+	blocked    atomic.Int64
+	latency    latencyWindow
+	metrics    metrics
+	name       string
+	onEvent    func(Event)
+	onResolve  func(err error) // called with every job's final error, may be nil
+	clock      Clock
+	progress   progress
+}
+
+// workerIDKey is the context key under which a worker stores its ID
+type workerIDKey struct{}
+
+// WorkerID returns the ID of the worker running the job, or -1 when ctx
+// does not come from a pool worker
+func WorkerID(ctx context.Context) int {
+	if id, ok := ctx.Value(workerIDKey{}).(int); ok {
+		return id
+	}
+	return -1
+}
+
+// New creates a pool and starts numWorkers workers that call handler for
+// every submitted job
+func New[J, R any](numWorkers int, handler Handler[J, R], opts ...Option) *Pool[J, R] {
+	if numWorkers < 1 {
+		panic("workerpool: numWorkers must be at least 1")
+	}
+
+	cfg := config{
+		name:         "default",
+		queueSize:    numWorkers,
+		ctx:          context.Background(),
+		drainTimeout: DefaultDrainTimeout,
+		clock:        realClock{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	maxWorkers := numWorkers
+	var scale *Autoscale
+	if cfg.autoscale != nil {
+		s := cfg.autoscale.withDefaults(numWorkers)
+		numWorkers = max(s.MinWorkers, min(numWorkers, s.MaxWorkers))
+		maxWorkers = s.MaxWorkers
+		scale = &s
+	}
+
+	laneSize := cfg.keyLaneSize
+	if laneSize <= 0 {
+		laneSize = cfg.queueSize
+	}
+
+	// Jobs keep the parent's values but are only cancelled by the pool itself,
+	// so in-flight work can still finish after the parent is cancelled
+	ctx, cancel := context.WithCancel(context.WithoutCancel(cfg.ctx))
+	ctx = context.WithValue(ctx, clockKey{}, cfg.clock)
+	p := &Pool[J, R]{
+		handler:      handler,
+		created:      cfg.clock.Now(),
+		ctx:          ctx,
+		cancel:       cancel,
+		queue:        newPriorityQueue[J, R](cfg.aging),
+		queueSize:    max(cfg.queueSize, 1),
+		laneSize:     max(laneSize, 1),
+		overflow:     cfg.overflow,
+		ready:        make(chan struct{}, maxWorkers),
+		space:        make(chan struct{}),
+		retries:      make(map[*task[J, R]]Timer),
+		keys:         make(map[string][]*task[J, R]),
+		dedup:        newDedupTable[R](cfg.dedupTTL, cfg.dedupEntries),
+		retry:        cfg.retry,
+		jobTimeout:   cfg.jobTimeout,
+		journal:      cfg.journal,
+		closing:      make(chan struct{}),
+		drained:      make(chan struct{}),
+		stopping:     make(chan struct{}),
+		finished:     make(chan struct{}),
+		drainTimeout: cfg.drainTimeout,
+		scale:        scale,
+		onEvent:      cfg.onEvent,
+		onResolve:    cfg.onResolve,
+		name:         cfg.name,
+		metrics:      newMetrics(),
+		clock:        cfg.clock,
+	}
+	switch {
+	case cfg.tenants != nil:
+		p.fair = newFairQueue[J, R](cfg.tenants, cfg.aging, p.sinceCreated)
+		p.queue = p.fair
+	case cfg.stealing:
+		p.steal = newStealingQueue[J, R](maxWorkers)
+		p.queue = p.steal
+	}
+	if cfg.breaker != nil {
+		p.breakers = newBreakers(*cfg.breaker)
+	}
+	if cfg.deadLetters > 0 {
+		p.dead = newDeadLetterQueue[J](cfg.deadLetters)
+	}
+	if p.journal != nil {
+		p.replay()
+	}
+
+	// Start workers
+	p.workersMu.Lock()
+	for range numWorkers {
+		p.startWorkerLocked()
+	}
+	p.workersMu.Unlock()
+
+	if p.scale != nil {
+		go p.autoscale()
+	}
+	p.progress.start = p.clock.Now()
+	if cfg.onProgress != nil && cfg.progressInterval > 0 {
+		p.progress.reported = make(chan struct{})
+		go p.reportProgress(cfg.progressInterval, cfg.onProgress)
+	}
+
+	// Shut down gracefully once the parent context is cancelled
+	if cfg.ctx.Done() != nil {
+		go p.watch(cfg.ctx)
+	}
+
+	return p
+}
+
+// startWorkerLocked starts a new worker goroutine. The caller must hold
+// workersMu.
+func (p *Pool[J, R]) startWorkerLocked() {
+	id := p.nextWorker
+	p.nextWorker++
+	p.workers++
+	p.wg.Go(func() {
+		if p.worker(id) {
+			p.workersMu.Lock()
+			p.workers--
+			p.workersMu.Unlock()
+		}
+	})
+}
+
+// worker takes tasks from the queue until the pool is closed and drained,
+// or stops dispatching. It returns false when it was retired as idle, in
+// which case the worker count has already been updated.
+func (p *Pool[J, R]) worker(id int) bool {
+	ctx := context.WithValue(p.ctx, workerIDKey{}, id)
+
+	// Only autoscaled pools retire idle workers; a nil channel never fires
+	var idle Timer
+	var idleC <-chan time.Time
+	if p.scale != nil {
+		idle = p.clock.NewTimer(p.scale.IdleTimeout)
+		defer idle.Stop()
+		idleC = idle.C()
+	}
+
+	for {
+		t, ok := p.next(id)
+		if ok {
+			overran := p.run(ctx, t)
+			p.release()
+			if overran {
+				// The handler is still stuck in the background; hand this
+				// worker's slot to a fresh goroutine
+				p.replace(id)
+				return true
+			}
+			if idle != nil {
+				idle.Reset(p.scale.IdleTimeout)
+			}
+			continue
+		}
+
+		// Queue is empty: sleep until there is new work
+		select {
+		case <-p.ready:
+		case <-p.drained:
+			return true
+		case <-p.stopping:
+			return true
+		case <-idleC:
+			if p.retire() {
+				return false
+			}
+			idle.Reset(p.scale.IdleTimeout)
+		}
+	}
+}
+
+// next pops the next task from the queue and marks it in flight. Nothing is
+// popped once the pool stops dispatching.
+func (p *Pool[J, R]) next(id int) (*task[J, R], bool) {
+	if p.steal != nil {
+		return p.nextStealing(id)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isStopping() {
+		return nil, false
+	}
+	t, ok := p.queue.pop()
+	if !ok {
+		return nil, false
+	}
+	p.inflight.Add(1)
+	p.signalSpaceLocked()
+	return t, true
+}
+
+// signalSpaceLocked wakes submitters blocked on a full queue. The caller
+// must hold mu.
+func (p *Pool[J, R]) signalSpaceLocked() {
+	if p.spaceWaiters.Load() > 0 {
+		close(p.space)
+		p.space = make(chan struct{})
+	}
+}
+
+// release marks a task taken by next as no longer in flight
+func (p *Pool[J, R]) release() {
+	// Only a closed pool can become drained, so an open one skips the lock
+	if p.inflight.Add(-1) == 0 && p.closed.Load() {
+		p.mu.Lock()
+		p.checkDrainedLocked()
+		p.mu.Unlock()
+	}
+}
+
+// checkDrainedLocked closes drained once a closed pool has no work left.
+// The caller must hold mu.
+func (p *Pool[J, R]) checkDrainedLocked() {
+	if p.closed.Load() && p.queuedLocked() == 0 && p.inflight.Load() == 0 && len(p.retries) == 0 {
+		p.drainOnce.Do(func() {
+			close(p.drained)
+		})
+	}
+}
+
+// run calls the handler for one task and either resolves its future or
+// schedules a retry. It returns true when the handler overran its timeout
+// and was abandoned.
+func (p *Pool[J, R]) run(ctx context.Context, t *task[J, R]) bool {
+	p.busy.Add(1)
+	defer p.busy.Add(-1)
+
+	start := p.clock.Now()
+	t.attempts++
+	out, overran, rejected := p.guardedCall(ctx, t)
+	value, err := out.value, out.err
+	t.future.duration = p.clock.Now().Sub(start)
+	if !rejected {
+		p.latency.record(t.future.duration)
+		p.metrics.observe(WorkerID(ctx), t.future.duration)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		t.lastErr = err
+		if p.scheduleRetry(t) {
+			return overran
+		}
+		p.deadLetter(t)
+	}
+
+	if ctx.Err() != nil {
+		// Left unacknowledged so a journaled job is delivered again
+		p.cancelled.Add(1)
+	} else {
+		p.completed.Add(1)
+		p.journalAck(t)
+	}
+	p.metrics.finished(err)
+	t.future.resolve(value, err)
+	if overran && t.key != "" {
+		// The abandoned handler is still the key's active job
+		abandoned := t.abandoned
+		go func() {
+			<-abandoned
+			p.releaseKey(t)
+		}()
+	} else {
+		p.releaseKey(t)
+	}
+	return overran
+}
+
+// skip resolves a task that will never run
+func (p *Pool[J, R]) skip(t *task[J, R]) {
+	p.notStarted.Add(1)
+	t.future.resolve(*new(R), ErrNotStarted)
+}
+
+// isStopping reports whether Shutdown has stopped dispatching
+func (p *Pool[J, R]) isStopping() bool {
+	select {
+	case <-p.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// sinceCreated returns the pool's age on its clock
+func (p *Pool[J, R]) sinceCreated() time.Duration {
+	return p.clock.Now().Sub(p.created)
+}
+
+// queueLen returns the number of jobs waiting for a worker
+func (p *Pool[J, R]) queueLen() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queuedLocked()
+}
+
+// watch shuts the pool down when the parent context is done
+func (p *Pool[J, R]) watch(parent context.Context) {
+	select {
+	case <-parent.Done():
+		ctx, cancel := withTimeout(context.Background(), p.clock, p.drainTimeout)
+		defer cancel()
+		p.Shutdown(ctx)
+	case <-p.finished:
+	}
+}
+
+// Submit queues a job and returns a future for its result. When the queue
+// is full, the pool's overflow policy decides what happens (by default
+// Submit blocks until there is room, giving up when ctx is done).
+func (p *Pool[J, R]) Submit(ctx context.Context, job J, opts ...SubmitOption) (*Future[R], error) {
+	var sc submitConfig
+	for _, opt := range opts {
+		opt(&sc)
+	}
+
+	t := &task[J, R]{
+		job:        job,
+		future:     newFuture[R](p.nextID.Add(1)),
+		priority:   sc.priority,
+		timeout:    p.jobTimeout,
+		enqueuedAt: p.sinceCreated(),
+		key:        sc.key,
+		tenant:     sc.tenant,
+		circuit:    sc.circuit,
+	}
+	if sc.timeout > 0 {
+		t.timeout = sc.timeout
+	}
+	t.future.onDone = p.onResolve
+
+	if key := sc.idempotencyKey; key != "" {
+		if f, joined := p.dedup.join(key, t.future, p.clock.Now()); joined {
+			p.deduplicated.Add(1)
+			return f, nil
+		}
+		t.future.onDone = func(err error) {
+			p.dedup.complete(key, t.future, err, p.clock.Now())
+			if p.onResolve != nil {
+				p.onResolve(err)
+			}
+		}
+
+		// Duplicates may already be waiting on the future, so a failed
+		// submit must resolve it too
+		future, err := p.submit(ctx, t)
+		if err != nil {
+			t.future.resolve(*new(R), err)
+		}
+		return future, err
+	}
+	return p.submit(ctx, t)
+}
+
+// submit queues a task built by Submit
+func (p *Pool[J, R]) submit(ctx context.Context, t *task[J, R]) (*Future[R], error) {
+	p.metrics.submitted.Add(1)
+
+	// Make the job durable before anyone can see it
+	if err := p.journalEnqueue(t); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	var dropped *task[J, R]
+	if !p.closed.Load() && p.fullLocked(t) {
+		switch p.overflow {
+		case OverflowReject:
+			p.mu.Unlock()
+			p.rejected.Add(1)
+			p.journalAck(t)
+			return nil, ErrQueueFull
+
+		case OverflowDropNewest:
+			p.mu.Unlock()
+			p.dropped.Add(1)
+			p.journalAck(t)
+			t.future.resolve(*new(R), ErrDropped)
+			return t.future, nil
+
+		case OverflowDropOldest:
+			if _, parked := p.laneFullLocked(t); parked {
+				// Make room in the key's own lane
+				lane := p.keys[t.key]
+				dropped = lane[0]
+				p.keys[t.key] = lane[1:]
+				p.keyWaiting--
+				break
+			}
+			dropped, _ = p.queue.popOldest()
+			if dropped != nil && dropped.key != "" {
+				p.releaseKeyLocked(dropped.key)
+			}
+
+		case OverflowCallerRuns:
+			if _, parked := p.laneFullLocked(t); parked {
+				// Running now would overtake the key's earlier jobs, so wait
+				// for room in the lane instead
+				if err := p.waitForSpaceLocked(ctx, t); err != nil {
+					p.mu.Unlock()
+					p.journalAck(t)
+					return nil, err
+				}
+				break
+			}
+			if t.key != "" {
+				p.keys[t.key] = nil
+			}
+			p.inflight.Add(1)
+			p.mu.Unlock()
+			p.callerRuns.Add(1)
+			p.runInCaller(t)
+			return t.future, nil
+
+		default:
+			if err := p.waitForSpaceLocked(ctx, t); err != nil {
+				p.mu.Unlock()
+				p.journalAck(t)
+				return nil, err
+			}
+		}
+	}
+	if p.closed.Load() {
+		p.mu.Unlock()
+		p.journalAck(t)
+		return nil, ErrPoolClosed
+	}
+
+	queued := p.enqueueLocked(t, WorkerID(ctx))
+	p.mu.Unlock()
+
+	if dropped != nil {
+		p.dropped.Add(1)
+		p.journalAck(dropped)
+		dropped.future.resolve(*new(R), ErrDropped)
+	}
+
+	if queued {
+		p.wake()
+	}
+	return t.future, nil
+}
+
+// wake signals an idle worker, unless enough wake-ups are already pending
+func (p *Pool[J, R]) wake() {
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops accepting new jobs and waits until every queued job is done.
+// If a shutdown is already in progress, Close waits for it instead.
+func (p *Pool[J, R]) Close() Summary {
+	p.closeQueue()
+	p.wg.Wait()
+	return p.finish()
+}
+
+// Shutdown stops accepting and dispatching jobs, then waits for in-flight
+// jobs to finish. When ctx is done before they do, their context is
+// cancelled. Jobs still queued are resolved with ErrNotStarted.
+func (p *Pool[J, R]) Shutdown(ctx context.Context) Summary {
+	p.stopDispatch()
+	p.closeQueue()
+
+	// Wait for all workers to finish, or cancel them at the drain deadline
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+	}
+
+	return p.finish()
+}
+
+// stopDispatch makes the workers stop taking jobs from the queue
+func (p *Pool[J, R]) stopDispatch() {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+	})
+}
+
+// closeQueue rejects further submissions and wakes idle workers so they
+// exit once the queue is drained
+func (p *Pool[J, R]) closeQueue() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed.Store(true)
+		p.checkDrainedLocked()
+		p.mu.Unlock()
+		close(p.closing)
+	})
+}
+
+// finish resolves jobs that were never started and builds the summary. It
+// must only be called once all workers have exited.
+func (p *Pool[J, R]) finish() Summary {
+	p.finishOnce.Do(func() {
+		p.mu.Lock()
+		p.done = true
+		for t, ok := p.queue.pop(); ok; t, ok = p.queue.pop() {
+			p.skip(t)
+		}
+		for key := range p.keys {
+			p.skipLaneLocked(key)
+		}
+
+		// Give up on retries that are still waiting for their backoff. A
+		// timer that already fired sees done and resolves the task itself.
+		for t, timer := range p.retries {
+			if timer.Stop() {
+				delete(p.retries, t)
+				p.completed.Add(1)
+				p.metrics.finished(t.lastErr)
+				t.future.resolve(*new(R), t.lastErr)
+			}
+		}
+		p.mu.Unlock()
+		p.cancel()
+		p.progress.end = p.clock.Now()
+		close(p.finished)
+	})
+	<-p.finished
+	if p.progress.reported != nil {
+		<-p.progress.reported // Let the final progress report through first
+	}
+
+	return Summary{
+		Completed:  int(p.completed.Load()),
+		Cancelled:  int(p.cancelled.Load()),
+		NotStarted: int(p.notStarted.Load()),
+	}
+}
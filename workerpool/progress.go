@@ -0,0 +1,189 @@
+package workerpool
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressWindow is how far back the throughput estimate looks, so the ETA
+// follows the current speed rather than the average since the start
+const progressWindow = 10 * time.Second
+
+// Progress is a snapshot of how far the pool has come through its jobs
+type Progress struct {
+	Total     int           // jobs expected (see SetTotal), at least the jobs submitted
+	Done      int           // jobs with a final result: Succeeded + Failed + Skipped
+	Succeeded int           // jobs that finished without an error
+	Failed    int           // jobs that finished with an error
+	Skipped   int           // jobs rejected, dropped or never started
+	Elapsed   time.Duration // time since the pool was created
+	Rate      float64       // jobs finished per second, over the last few seconds
+	ETA       time.Duration // estimated time until Done reaches Total (0 when unknown)
+	Closed    bool          // the pool has finished; this is the last report
+}
+
+// Percent returns Done as a percentage of Total
+func (pr Progress) Percent() float64 {
+	if pr.Total == 0 {
+		return 0
+	}
+	return 100 * float64(pr.Done) / float64(pr.Total)
+}
+
+// String formats the progress as one line, e.g.
+// "420/1000 (42.0%) 85.3 jobs/s, 3 failed, ETA 7s"
+func (pr Progress) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%d (%.1f%%) %.1f jobs/s", pr.Done, pr.Total, pr.Percent(), pr.Rate)
+	if pr.Failed > 0 {
+		fmt.Fprintf(&b, ", %d failed", pr.Failed)
+	}
+	if pr.Skipped > 0 {
+		fmt.Fprintf(&b, ", %d skipped", pr.Skipped)
+	}
+	switch {
+	case pr.Closed || pr.Done == pr.Total:
+		fmt.Fprintf(&b, ", took %v", pr.Elapsed.Round(100*time.Millisecond))
+	case pr.ETA >= 10*time.Second:
+		fmt.Fprintf(&b, ", ETA %v", pr.ETA.Round(time.Second))
+	case pr.ETA > 0:
+		fmt.Fprintf(&b, ", ETA %v", pr.ETA.Round(100*time.Millisecond))
+	}
+	return b.String()
+}
+
+// WithProgress calls fn with the pool's progress every interval, and once
+// more when the pool finishes, before Close returns. fn runs on its own
+// goroutine, so it may print or log without slowing down the workers, but
+// it must not close the pool.
+func WithProgress(interval time.Duration, fn func(Progress)) Option {
+	return func(c *config) {
+		c.progressInterval = interval
+		c.onProgress = fn
+	}
+}
+
+// ProgressBar returns a WithProgress callback that redraws a one-line
+// progress bar on w, typically os.Stderr
+func ProgressBar(w io.Writer) func(Progress) {
+	const width = 30
+	return func(pr Progress) {
+		filled := 0
+		if pr.Total > 0 {
+			filled = min(width, width*pr.Done/pr.Total)
+		}
+		bar := strings.Repeat("#", filled) + strings.Repeat("-", width-filled)
+
+		// Clear the rest of the previous, possibly longer, line
+		fmt.Fprintf(w, "\r[%s] %s\033[K", bar, pr)
+		if pr.Closed {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// progressSample is the done count at one point in time
+type progressSample struct {
+	at   time.Time
+	done int
+}
+
+// progress tracks what Progress needs beyond the pool's counters
+type progress struct {
+	start    time.Time
+	total    atomic.Int64
+	end      time.Time     // set when the pool finishes
+	reported chan struct{} // closed after the final report, if there is a reporter
+
+	mu      sync.Mutex
+	samples []progressSample // oldest first, spanning about progressWindow
+}
+
+// SetTotal sets how many jobs the caller is going to submit, so that
+// Progress can report a percentage and an ETA from the very first job
+func (p *Pool[J, R]) SetTotal(n int) {
+	p.progress.total.Store(int64(n))
+}
+
+// Progress returns how far the pool has come through its jobs
+func (p *Pool[J, R]) Progress() Progress {
+	pr := Progress{
+		Succeeded: int(p.metrics.succeeded.Load()),
+		Failed:    int(p.metrics.failed.Load()),
+		Skipped:   int(p.rejected.Load() + p.dropped.Load() + p.notStarted.Load()),
+		Total:     max(int(p.progress.total.Load()), int(p.metrics.submitted.Load())),
+		Closed:    p.isFinished(),
+	}
+	pr.Done = pr.Succeeded + pr.Failed + pr.Skipped
+
+	now := p.clock.Now()
+	if pr.Closed {
+		// Freeze the numbers at the moment the pool finished
+		pr.Elapsed = p.progress.end.Sub(p.progress.start)
+		if pr.Elapsed > 0 {
+			pr.Rate = float64(pr.Done) / pr.Elapsed.Seconds()
+		}
+		return pr
+	}
+	pr.Elapsed = now.Sub(p.progress.start)
+	pr.Rate = p.progress.rate(now, pr.Done)
+	if remaining := pr.Total - pr.Done; remaining > 0 && pr.Rate > 0 {
+		pr.ETA = time.Duration(float64(remaining) / pr.Rate * float64(time.Second))
+	}
+	return pr
+}
+
+// rate records a sample and returns the jobs per second over the window
+func (t *progress) rate(now time.Time, done int) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Keep at most one sample per second, and none older than the window
+	if n := len(t.samples); n == 0 || now.Sub(t.samples[n-1].at) >= time.Second {
+		t.samples = append(t.samples, progressSample{at: now, done: done})
+	}
+	for len(t.samples) > 1 && now.Sub(t.samples[0].at) > progressWindow {
+		t.samples = t.samples[1:]
+	}
+
+	// Until there is a second of history, use the average since the start
+	from := progressSample{at: t.start}
+	if oldest := t.samples[0]; now.Sub(oldest.at) >= time.Second {
+		from = oldest
+	}
+	span := now.Sub(from.at).Seconds()
+	if span <= 0 {
+		return 0
+	}
+	return float64(done-from.done) / span
+}
+
+// isFinished reports whether finish has run
+func (p *Pool[J, R]) isFinished() bool {
+	select {
+	case <-p.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+// reportProgress calls fn every interval until the pool finishes
+func (p *Pool[J, R]) reportProgress(interval time.Duration, fn func(Progress)) {
+	for {
+		timer := p.clock.NewTimer(interval)
+		select {
+		case <-timer.C():
+			fn(p.Progress())
+		case <-p.finished:
+			timer.Stop()
+			fn(p.Progress())
+			close(p.progress.reported)
+			return
+		}
+	}
+}
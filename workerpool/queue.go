@@ -0,0 +1,100 @@
+package workerpool
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Priority levels for WithPriority. Any int works; higher runs first.
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+// queue holds tasks that are waiting for a worker. Implementations are not
+// safe for concurrent use; the pool guards them with its mutex.
+type queue[J, R any] interface {
+	push(t *task[J, R])
+	pop() (*task[J, R], bool)
+	popOldest() (*task[J, R], bool) // removes the earliest submitted task
+	len() int
+}
+
+// priorityQueue pops the task with the highest priority first and keeps
+// FIFO order between equal priorities.
+//
+// With aging enabled a waiting task gains one priority level per aging
+// interval. Because every task ages at the same rate, comparing
+// priority - enqueuedAt/aging gives the same order at any point in time, so
+// the heap never needs re-sorting.
+type priorityQueue[J, R any] struct {
+	tasks taskHeap[J, R]
+}
+
+func newPriorityQueue[J, R any](aging time.Duration) *priorityQueue[J, R] {
+	return &priorityQueue[J, R]{tasks: taskHeap[J, R]{aging: aging}}
+}
+
+func (q *priorityQueue[J, R]) push(t *task[J, R]) {
+	heap.Push(&q.tasks, t)
+}
+
+func (q *priorityQueue[J, R]) pop() (*task[J, R], bool) {
+	if len(q.tasks.items) == 0 {
+		return nil, false
+	}
+	return heap.Pop(&q.tasks).(*task[J, R]), true
+}
+
+func (q *priorityQueue[J, R]) popOldest() (*task[J, R], bool) {
+	if len(q.tasks.items) == 0 {
+		return nil, false
+	}
+	oldest := 0
+	for i, t := range q.tasks.items {
+		if t.future.id < q.tasks.items[oldest].future.id {
+			oldest = i
+		}
+	}
+	return heap.Remove(&q.tasks, oldest).(*task[J, R]), true
+}
+
+func (q *priorityQueue[J, R]) len() int {
+	return len(q.tasks.items)
+}
+
+// taskHeap implements heap.Interface for priorityQueue
+type taskHeap[J, R any] struct {
+	items []*task[J, R]
+	aging time.Duration
+}
+
+func (h taskHeap[J, R]) Len() int { return len(h.items) }
+
+func (h taskHeap[J, R]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.aging > 0 {
+		// Compare priority*aging - enqueuedAt, i.e. the aged priority scaled by aging
+		sa := int64(a.priority)*int64(h.aging) - int64(a.enqueuedAt)
+		sb := int64(b.priority)*int64(h.aging) - int64(b.enqueuedAt)
+		if sa != sb {
+			return sa > sb
+		}
+	} else if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.future.id < b.future.id
+}
+
+func (h taskHeap[J, R]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *taskHeap[J, R]) Push(x any) { h.items = append(h.items, x.(*task[J, R])) }
+
+func (h *taskHeap[J, R]) Pop() any {
+	n := len(h.items)
+	t := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	return t
+}
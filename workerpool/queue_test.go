@@ -0,0 +1,113 @@
+package workerpool
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderPool is a one-worker pool that records the order in which jobs run.
+// Its worker is held on a first job until release is called, so every job
+// submitted before that is queued and ordered by the queue alone.
+type orderPool struct {
+	*Pool[string, string]
+	release func()
+
+	mu  sync.Mutex
+	ran []string
+}
+
+func newOrderPool(t *testing.T, opts ...Option) *orderPool {
+	t.Helper()
+	op := &orderPool{}
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	op.Pool = New(1, func(ctx context.Context, job string) (string, error) {
+		if job == "gate" {
+			close(started)
+			<-gate
+			return job, nil
+		}
+		op.mu.Lock()
+		op.ran = append(op.ran, job)
+		op.mu.Unlock()
+		return job, nil
+	}, append([]Option{WithQueueSize(100)}, opts...)...)
+	op.release = sync.OnceFunc(func() { close(gate) })
+	t.Cleanup(op.release)
+
+	if _, err := op.Submit(context.Background(), "gate"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	return op
+}
+
+// submit queues job with the given priority
+func (op *orderPool) submit(t *testing.T, job string, priority int) {
+	t.Helper()
+	if _, err := op.Submit(context.Background(), job, WithPriority(priority)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// order releases the worker, waits for every job and returns their order
+func (op *orderPool) order() []string {
+	op.release()
+	op.Close()
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	return op.ran
+}
+
+func TestPriorityOrder(t *testing.T) {
+	op := newOrderPool(t)
+	op.submit(t, "normal-1", PriorityNormal)
+	op.submit(t, "low", PriorityLow)
+	op.submit(t, "high-1", PriorityHigh)
+	op.submit(t, "normal-2", PriorityNormal)
+	op.submit(t, "high-2", PriorityHigh)
+	op.submit(t, "urgent", 100)
+
+	want := []string{"urgent", "high-1", "high-2", "normal-1", "normal-2", "low"}
+	if got := op.order(); !slices.Equal(got, want) {
+		t.Fatalf("order %v, want %v", got, want)
+	}
+}
+
+func TestAgingOrder(t *testing.T) {
+	// A low job gains a level every 10s, so after 100s it ties with a
+	// normal one submitted at that moment and wins on submission order
+	for _, tc := range []struct {
+		waited time.Duration
+		want   []string
+	}{
+		{99 * time.Second, []string{"normal", "low"}},
+		{100 * time.Second, []string{"low", "normal"}},
+		{101 * time.Second, []string{"low", "normal"}},
+	} {
+		clock := NewFakeClock(epoch)
+		op := newOrderPool(t, WithClock(clock), WithAging(10*time.Second))
+		op.submit(t, "low", PriorityLow)
+		clock.Advance(tc.waited)
+		op.submit(t, "normal", PriorityNormal)
+
+		if got := op.order(); !slices.Equal(got, tc.want) {
+			t.Errorf("after %v: order %v, want %v", tc.waited, got, tc.want)
+		}
+	}
+}
+
+func TestNoAgingWithoutOption(t *testing.T) {
+	clock := NewFakeClock(epoch)
+	op := newOrderPool(t, WithClock(clock))
+	op.submit(t, "low", PriorityLow)
+	clock.Advance(24 * time.Hour)
+	op.submit(t, "normal", PriorityNormal)
+
+	if got, want := op.order(), []string{"normal", "low"}; !slices.Equal(got, want) {
+		t.Fatalf("order %v, want %v", got, want)
+	}
+}
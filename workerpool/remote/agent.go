@@ -0,0 +1,208 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// errGone is returned by post when the coordinator answers 410
+var errGone = errors.New("remote: lease is gone")
+
+// Agent runs jobs leased from a Coordinator. Each of its workers keeps one
+// lease at a time: it asks for a job, runs the handler while renewing the
+// lease, and reports the result.
+type Agent[J, R any] struct {
+	url     string
+	workers int
+	handler workerpool.Handler[J, R]
+
+	// ID names the agent in leases and errors (default host-pid)
+	ID string
+	// Client sends the requests (default a client without a timeout; lease
+	// requests are long polls)
+	Client *http.Client
+	// RetryDelay is how long a worker waits after a failed request, e.g.
+	// while the coordinator is restarting (default 1s)
+	RetryDelay time.Duration
+	// OnError, when set, is told about failed requests
+	OnError func(error)
+	// Clock times heartbeats and retry delays (default the real clock), so
+	// tests can drive an agent with the same FakeClock as the coordinator
+	Clock workerpool.Clock
+}
+
+// NewAgent creates an agent for the coordinator served at url, e.g.
+// "http://127.0.0.1:8080", running up to workers jobs at once
+func NewAgent[J, R any](url string, workers int, handler workerpool.Handler[J, R]) *Agent[J, R] {
+	host, _ := os.Hostname()
+	return &Agent[J, R]{
+		url:        strings.TrimSuffix(url, "/"),
+		workers:    max(workers, 1),
+		handler:    handler,
+		ID:         fmt.Sprintf("%s-%d", host, os.Getpid()),
+		Client:     &http.Client{},
+		RetryDelay: time.Second,
+		Clock:      workerpool.RealClock(),
+	}
+}
+
+// Run works on jobs until ctx is cancelled. Jobs still running then are
+// abandoned; their leases expire and the coordinator gives them to
+// another agent.
+func (a *Agent[J, R]) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range a.workers {
+		wg.Go(func() {
+			a.work(ctx)
+		})
+	}
+	wg.Wait()
+}
+
+// work is the loop of one agent worker
+func (a *Agent[J, R]) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		var lease leaseResponse
+		ok, err := a.post(ctx, "/lease", leaseRequest{Agent: a.ID}, &lease)
+		if err != nil {
+			a.fail(ctx, err)
+			continue
+		}
+		if ok {
+			a.run(ctx, lease)
+		}
+	}
+}
+
+// run executes one leased job and reports its result
+func (a *Agent[J, R]) run(ctx context.Context, lease leaseResponse) {
+	report := completeRequest{Lease: lease.Lease}
+	var job J
+	if err := json.Unmarshal(lease.Job, &job); err != nil {
+		report.Error = fmt.Sprintf("decode job: %v", err)
+	} else {
+		// Renew the lease while the handler runs, and stop the handler if
+		// the lease is lost
+		jobCtx, cancel := context.WithCancelCause(ctx)
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			a.heartbeat(jobCtx, cancel, lease)
+		})
+		value, err := a.call(jobCtx, job)
+		cancel(nil)
+		wg.Wait()
+
+		if ctx.Err() != nil || errors.Is(context.Cause(jobCtx), ErrLeaseLost) {
+			return // Someone else gets the job
+		}
+		if err != nil {
+			report.Error = err.Error()
+		} else if report.Result, err = json.Marshal(value); err != nil {
+			report.Error = fmt.Sprintf("encode result: %v", err)
+		}
+	}
+
+	// A stale lease (410) means the result is no longer wanted
+	if _, err := a.post(ctx, "/complete", report, nil); err != nil && !errors.Is(err, errGone) {
+		a.fail(ctx, err)
+	}
+}
+
+// call runs the handler, turning a panic into an error
+func (a *Agent[J, R]) call(ctx context.Context, job J) (value R, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return a.handler(ctx, job)
+}
+
+// heartbeat renews the lease every third of its TTL until ctx is done
+func (a *Agent[J, R]) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, lease leaseResponse) {
+	interval := max(time.Duration(lease.TTLMS)*time.Millisecond/3, 10*time.Millisecond)
+	ticker := a.Clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			_, err := a.post(ctx, "/heartbeat", heartbeatRequest{Lease: lease.Lease}, nil)
+			if errors.Is(err, errGone) {
+				cancel(ErrLeaseLost)
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				// Keep working; the next heartbeat may get through in time
+				a.report(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// post sends req as JSON and decodes a 200 response into resp. It returns
+// false for 204 No Content and errGone for 410 Gone.
+func (a *Agent[J, R]) post(ctx context.Context, path string, req, resp any) (bool, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url+path, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := a.Client.Do(httpReq)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		if resp == nil {
+			return true, nil
+		}
+		return true, json.NewDecoder(res.Body).Decode(resp)
+	case http.StatusNoContent:
+		return false, nil
+	case http.StatusGone:
+		return false, errGone
+	default:
+		return false, fmt.Errorf("remote: %s: %s", path, res.Status)
+	}
+}
+
+// fail reports err and waits RetryDelay before the worker tries again
+func (a *Agent[J, R]) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	a.report(err)
+	timer := a.Clock.NewTimer(a.RetryDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+	case <-ctx.Done():
+	}
+}
+
+// report passes err to OnError, if set
+func (a *Agent[J, R]) report(err error) {
+	if a.OnError != nil {
+		a.OnError(err)
+	}
+}
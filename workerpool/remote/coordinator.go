@@ -0,0 +1,434 @@
+// Package remote spreads a workerpool across processes. A Coordinator owns
+// the job queue and hands jobs out over HTTP; Agents in other processes
+// lease jobs, send heartbeats while they work, and report the results.
+// A lease that is not renewed in time expires and its job is queued again,
+// so the jobs of a crashed agent are picked up by the others.
+//
+// The protocol is three JSON endpoints, all POST:
+//
+//	/lease      {"agent"}                    -> 200 {"lease", "job", "ttl_ms"}, 204 when no job came up, or 503 once closed
+//	/heartbeat  {"lease"}                    -> 204, or 410 when the lease is gone
+//	/complete   {"lease", "result", "error"} -> 204, or 410 when the lease is gone
+package remote
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// ErrLeaseLost is the cause of an agent's job context when the coordinator
+// no longer knows the lease, e.g. because it expired
+var ErrLeaseLost = errors.New("remote: lease lost")
+
+// Defaults for Config
+const (
+	DefaultCapacity    = 64
+	DefaultLeaseTTL    = 10 * time.Second
+	DefaultPollTimeout = 30 * time.Second
+)
+
+// Config configures a Coordinator
+type Config struct {
+	Capacity    int              // jobs leased to agents at once (default DefaultCapacity)
+	LeaseTTL    time.Duration    // how long a lease lives without a heartbeat (default DefaultLeaseTTL)
+	PollTimeout time.Duration    // how long a lease request waits for a job (default DefaultPollTimeout)
+	Clock       workerpool.Clock // time source for leases and the pool (default the real clock)
+}
+
+// RemoteError is the error an agent reported for a job
+type RemoteError struct {
+	Agent   string
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote: agent %s: %s", e.Agent, e.Message)
+}
+
+// Lease describes a job that an agent is working on
+type Lease struct {
+	ID       uint64
+	Agent    string
+	Deadline time.Time // when the lease expires unless renewed
+}
+
+// CoordinatorStats counts lease activity
+type CoordinatorStats struct {
+	Leased    int // leases granted
+	Completed int // leases that reported a result
+	Expired   int // leases that ran out; their jobs were queued again
+	Stale     int // heartbeats and results for leases that were already gone
+}
+
+// lease is a job handed to an agent, or waiting to be
+type lease struct {
+	id       uint64
+	agent    string
+	job      json.RawMessage
+	deadline time.Time
+	revoked  bool                 // the job gave up before an agent took it
+	result   chan completeRequest // receives the agent's report
+}
+
+// Protocol messages
+type (
+	leaseRequest struct {
+		Agent string `json:"agent"`
+	}
+	leaseResponse struct {
+		Lease uint64          `json:"lease"`
+		Job   json.RawMessage `json:"job"`
+		TTLMS int64           `json:"ttl_ms"`
+	}
+	heartbeatRequest struct {
+		Lease uint64 `json:"lease"`
+	}
+	completeRequest struct {
+		Lease  uint64          `json:"lease"`
+		Result json.RawMessage `json:"result,omitempty"`
+		Error  string          `json:"error,omitempty"`
+	}
+)
+
+// Coordinator queues jobs in a workerpool and serves them to remote agents.
+// Every pool worker stands for one lease, so Capacity bounds how many jobs
+// are out at once; the pool's own options (queue size, retries, timeouts,
+// dead letters, metrics) apply as usual. Jobs and results must be JSON
+// encodable.
+type Coordinator[J, R any] struct {
+	pool        *workerpool.Pool[J, R]
+	clock       workerpool.Clock
+	ttl         time.Duration
+	pollTimeout time.Duration
+	mux         *http.ServeMux
+
+	offers chan *lease // jobs waiting for an agent
+	closed chan struct{}
+	once   sync.Once
+
+	mu     sync.Mutex
+	leases map[uint64]*lease
+	nextID uint64
+
+	leased    atomic.Int64
+	completed atomic.Int64
+	expired   atomic.Int64
+	stale     atomic.Int64
+}
+
+// NewCoordinator creates a coordinator. Serve it with an http.Server (it is
+// an http.Handler) and submit jobs with Submit.
+func NewCoordinator[J, R any](cfg Config, opts ...workerpool.Option) *Coordinator[J, R] {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCapacity
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = DefaultLeaseTTL
+	}
+	if cfg.PollTimeout <= 0 {
+		cfg.PollTimeout = DefaultPollTimeout
+	}
+	if cfg.Clock != nil {
+		opts = append([]workerpool.Option{workerpool.WithClock(cfg.Clock)}, opts...)
+	} else {
+		cfg.Clock = workerpool.RealClock()
+	}
+
+	c := &Coordinator[J, R]{
+		clock:       cfg.Clock,
+		ttl:         cfg.LeaseTTL,
+		pollTimeout: cfg.PollTimeout,
+		mux:         http.NewServeMux(),
+		offers:      make(chan *lease),
+		closed:      make(chan struct{}),
+		leases:      make(map[uint64]*lease),
+	}
+	c.pool = workerpool.New(cfg.Capacity, c.dispatch, opts...)
+
+	c.mux.HandleFunc("POST /lease", c.serveLease)
+	c.mux.HandleFunc("POST /heartbeat", c.serveHeartbeat)
+	c.mux.HandleFunc("POST /complete", c.serveComplete)
+	return c
+}
+
+// ServeHTTP serves the agent protocol
+func (c *Coordinator[J, R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mux.ServeHTTP(w, r)
+}
+
+// Submit queues a job for the agents
+func (c *Coordinator[J, R]) Submit(ctx context.Context, job J, opts ...workerpool.SubmitOption) (*workerpool.Future[R], error) {
+	return c.pool.Submit(ctx, job, opts...)
+}
+
+// Pool returns the pool behind the coordinator, for its stats, progress,
+// dead letters and the like
+func (c *Coordinator[J, R]) Pool() *workerpool.Pool[J, R] {
+	return c.pool
+}
+
+// Close stops accepting jobs and waits until the agents have finished every
+// queued job. Agents must keep running until Close returns.
+func (c *Coordinator[J, R]) Close() workerpool.Summary {
+	defer c.stopPolling()
+	return c.pool.Close()
+}
+
+// Shutdown stops handing out jobs and waits for the leased ones until ctx
+// is done; see workerpool.Pool.Shutdown
+func (c *Coordinator[J, R]) Shutdown(ctx context.Context) workerpool.Summary {
+	defer c.stopPolling()
+	return c.pool.Shutdown(ctx)
+}
+
+// stopPolling answers waiting lease requests once the pool is done
+func (c *Coordinator[J, R]) stopPolling() {
+	c.once.Do(func() {
+		close(c.closed)
+	})
+}
+
+// Leases returns the jobs currently leased to agents, oldest first
+func (c *Coordinator[J, R]) Leases() []Lease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	leases := make([]Lease, 0, len(c.leases))
+	for _, l := range c.leases {
+		leases = append(leases, Lease{ID: l.id, Agent: l.agent, Deadline: l.deadline})
+	}
+	slices.SortFunc(leases, func(a, b Lease) int { return cmp.Compare(a.ID, b.ID) })
+	return leases
+}
+
+// Stats returns the lease counters
+func (c *Coordinator[J, R]) Stats() CoordinatorStats {
+	return CoordinatorStats{
+		Leased:    int(c.leased.Load()),
+		Completed: int(c.completed.Load()),
+		Expired:   int(c.expired.Load()),
+		Stale:     int(c.stale.Load()),
+	}
+}
+
+// dispatch is the pool handler: it offers the job to the agents and waits
+// for the result, offering it again whenever a lease expires
+func (c *Coordinator[J, R]) dispatch(ctx context.Context, job J) (R, error) {
+	var zero R
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return zero, workerpool.Permanent(fmt.Errorf("remote: encode job: %w", err))
+	}
+
+	for {
+		// Wait for an agent to take the job
+		l := &lease{job: raw, result: make(chan completeRequest, 1)}
+		select {
+		case c.offers <- l:
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+
+		report, ok := c.await(ctx, l)
+		if !ok {
+			if ctx.Err() != nil {
+				return zero, ctx.Err()
+			}
+			continue // The lease expired; offer the job again
+		}
+
+		if report.Error != "" {
+			return zero, &RemoteError{Agent: l.agent, Message: report.Error}
+		}
+		var value R
+		if err := json.Unmarshal(report.Result, &value); err != nil {
+			return zero, workerpool.Permanent(fmt.Errorf("remote: decode result from agent %s: %w", l.agent, err))
+		}
+		return value, nil
+	}
+}
+
+// await waits for the agent's report. It returns false when the lease
+// expired or ctx was cancelled, after revoking the lease.
+func (c *Coordinator[J, R]) await(ctx context.Context, l *lease) (completeRequest, bool) {
+	timer := c.clock.NewTimer(c.ttl)
+	defer timer.Stop()
+
+	for {
+		select {
+		case report := <-l.result:
+			return report, true
+		case <-timer.C():
+			// Heartbeats move the deadline; only expire once it has passed
+			if remaining := c.expire(l); remaining > 0 {
+				timer.Reset(remaining)
+				continue
+			}
+			select {
+			case report := <-l.result: // Reported just in time
+				return report, true
+			default:
+				return completeRequest{}, false
+			}
+		case <-ctx.Done():
+			c.revoke(l)
+			return completeRequest{}, false
+		}
+	}
+}
+
+// expire removes the lease if its deadline has passed, otherwise it returns
+// the time left
+func (c *Coordinator[J, R]) expire(l *lease) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.leases[l.id]; !ok {
+		return 0 // Already completed or expired by a late heartbeat
+	}
+	if remaining := l.deadline.Sub(c.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	delete(c.leases, l.id)
+	c.expired.Add(1)
+	return 0
+}
+
+// revoke withdraws a lease whose job was cancelled; the agent learns about
+// it from its next heartbeat
+func (c *Coordinator[J, R]) revoke(l *lease) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l.revoked = true
+	delete(c.leases, l.id)
+}
+
+// grant records that agent took the offered job. It returns false when the
+// job was cancelled in the meantime.
+func (c *Coordinator[J, R]) grant(l *lease, agent string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l.revoked {
+		return false
+	}
+	c.nextID++
+	l.id = c.nextID
+	l.agent = agent
+	l.deadline = c.clock.Now().Add(c.ttl)
+	c.leases[l.id] = l
+	c.leased.Add(1)
+	return true
+}
+
+// take removes a live lease so its result can be delivered
+func (c *Coordinator[J, R]) take(id uint64) (*lease, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.leases[id]
+	if !ok || !c.clock.Now().Before(l.deadline) {
+		return nil, false
+	}
+	delete(c.leases, id)
+	return l, true
+}
+
+// renew pushes a live lease's deadline out by the TTL
+func (c *Coordinator[J, R]) renew(id uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.leases[id]
+	if !ok || !c.clock.Now().Before(l.deadline) {
+		return false
+	}
+	l.deadline = c.clock.Now().Add(c.ttl)
+	return true
+}
+
+// serveLease hands the next job to an agent, waiting up to the poll timeout
+// for one to come up
+func (c *Coordinator[J, R]) serveLease(w http.ResponseWriter, r *http.Request) {
+	var req leaseRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	timer := c.clock.NewTimer(c.pollTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case l := <-c.offers:
+			if !c.grant(l, req.Agent) {
+				continue
+			}
+			writeJSON(w, leaseResponse{Lease: l.id, Job: l.job, TTLMS: c.ttl.Milliseconds()})
+			return
+		case <-c.closed:
+			// Agents back off instead of polling a closed coordinator in a loop
+			http.Error(w, "coordinator is closed", http.StatusServiceUnavailable)
+		case <-timer.C():
+			w.WriteHeader(http.StatusNoContent)
+		case <-r.Context().Done():
+		}
+		return
+	}
+}
+
+// serveHeartbeat renews a lease
+func (c *Coordinator[J, R]) serveHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req heartbeatRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if !c.renew(req.Lease) {
+		c.stale.Add(1)
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveComplete delivers an agent's result to the waiting job
+func (c *Coordinator[J, R]) serveComplete(w http.ResponseWriter, r *http.Request) {
+	var req completeRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	l, ok := c.take(req.Lease)
+	if !ok {
+		// The job was already given to someone else; drop the late result
+		c.stale.Add(1)
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	c.completed.Add(1)
+	l.result <- req
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decode reads a JSON request body, answering 400 when it is malformed
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<20)).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as the response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v) // An agent that went away just lets its lease expire
+}
@@ -0,0 +1,188 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NatthawutSkc2015/go-programming/workerpool"
+)
+
+// ttl is the lease TTL of test coordinators; agents renew every third of it
+const ttl = 200 * time.Millisecond
+
+// serve starts a coordinator for int jobs on an httptest server
+func serve(t *testing.T) (*Coordinator[int, string], string) {
+	t.Helper()
+	c := NewCoordinator[int, string](Config{Capacity: 4, LeaseTTL: ttl, PollTimeout: 50 * time.Millisecond},
+		workerpool.WithQueueSize(16))
+	srv := httptest.NewServer(c)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		c.Shutdown(ctx)
+		srv.Close()
+	})
+	return c, srv.URL
+}
+
+// startAgent runs an agent named id until the returned stop is called or the
+// test ends. stop returns once the agent has stopped.
+func startAgent(t *testing.T, url, id string, handler workerpool.Handler[int, string], client *http.Client) (stop func()) {
+	t.Helper()
+	agent := NewAgent(url, 1, handler)
+	agent.ID = id
+	agent.RetryDelay = 10 * time.Millisecond
+	if client != nil {
+		agent.Client = client
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		agent.Run(ctx)
+	}()
+	stop = sync.OnceFunc(func() {
+		cancel()
+		<-done
+	})
+	t.Cleanup(stop)
+	return stop
+}
+
+// answer is a handler that reports which agent ran the job
+func answer(id string) workerpool.Handler[int, string] {
+	return func(ctx context.Context, job int) (string, error) {
+		return id, nil
+	}
+}
+
+// wait returns the future's result, failing the test if it takes too long
+func wait(t *testing.T, future *workerpool.Future[string]) (string, error) {
+	t.Helper()
+	select {
+	case <-future.Done():
+		return future.Wait()
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not finish")
+		return "", nil
+	}
+}
+
+// noHeartbeats fails heartbeat requests, as if the agent were hung
+type noHeartbeats struct{}
+
+func (noHeartbeats) RoundTrip(r *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(r.URL.Path, "/heartbeat") {
+		return nil, errors.New("hung")
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestCrashedAgentsJobGoesToAnother(t *testing.T) {
+	c, url := serve(t)
+	future, err := c.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first agent takes the job and crashes mid-run
+	leased := make(chan struct{})
+	crash := startAgent(t, url, "crashed", func(ctx context.Context, job int) (string, error) {
+		close(leased)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}, nil)
+	<-leased
+	crash()
+	if leases := c.Leases(); len(leases) != 1 || leases[0].Agent != "crashed" {
+		t.Fatalf("leases after the crash = %+v, want one held by the crashed agent", leases)
+	}
+
+	startAgent(t, url, "healthy", answer("healthy"), nil)
+	if value, err := wait(t, future); err != nil || value != "healthy" {
+		t.Fatalf("result = %q, %v; want %q, nil", value, err, "healthy")
+	}
+	stats := c.Stats()
+	if stats.Leased != 2 || stats.Expired != 1 || stats.Completed != 1 {
+		t.Fatalf("stats = %+v, want 2 leased, 1 expired, 1 completed", stats)
+	}
+}
+
+func TestHungAgentsLateResultIsDropped(t *testing.T) {
+	c, url := serve(t)
+	future, err := c.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first agent cannot renew its lease and finishes after it expired
+	leased := make(chan struct{})
+	late := make(chan struct{})
+	startAgent(t, url, "hung", func(ctx context.Context, job int) (string, error) {
+		close(leased)
+		<-late
+		return "hung", nil
+	}, &http.Client{Transport: noHeartbeats{}})
+	<-leased
+
+	startAgent(t, url, "healthy", answer("healthy"), nil)
+	if value, err := wait(t, future); err != nil || value != "healthy" {
+		t.Fatalf("result = %q, %v; want %q, nil", value, err, "healthy")
+	}
+
+	close(late)
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Stale == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the hung agent's late result was not counted as stale")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if stats := c.Stats(); stats.Expired != 1 || stats.Completed != 1 {
+		t.Fatalf("stats = %+v, want 1 expired, 1 completed", stats)
+	}
+}
+
+func TestHeartbeatsKeepLongJobLeased(t *testing.T) {
+	c, url := serve(t)
+	startAgent(t, url, "slow", func(ctx context.Context, job int) (string, error) {
+		if err := workerpool.Sleep(ctx, 3*ttl); err != nil {
+			return "", err
+		}
+		return "slow", nil
+	}, nil)
+
+	future, err := c.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := wait(t, future); err != nil || value != "slow" {
+		t.Fatalf("result = %q, %v; want %q, nil", value, err, "slow")
+	}
+	if stats := c.Stats(); stats.Leased != 1 || stats.Expired != 0 {
+		t.Fatalf("stats = %+v, want 1 leased, none expired", stats)
+	}
+}
+
+func TestRemoteErrorNamesAgent(t *testing.T) {
+	c, url := serve(t)
+	startAgent(t, url, "failing", func(ctx context.Context, job int) (string, error) {
+		return "", errors.New("boom")
+	}, nil)
+
+	future, err := c.Submit(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wait(t, future)
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) || remoteErr.Agent != "failing" || remoteErr.Message != "boom" {
+		t.Fatalf("err = %v, want a RemoteError from agent failing: boom", err)
+	}
+}
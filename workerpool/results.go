@@ -0,0 +1,138 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of a single job, tagged with the job it belongs to
+type Result[J, R any] struct {
+	ID       uint64        // sequence number assigned on submit (0 if never submitted)
+	Job      J             // the submitted job
+	Value    R             // value returned by the handler
+	Err      error         // error returned by the handler or by Submit
+	Duration time.Duration // time spent in the handler
+}
+
+// pendingJob is a submitted job whose result has not been emitted yet
+type pendingJob[J, R any] struct {
+	job    J
+	future *Future[R]
+	err    error
+}
+
+// result waits for the job and converts it into a Result
+func (pj pendingJob[J, R]) result() Result[J, R] {
+	if pj.future == nil {
+		return Result[J, R]{Job: pj.job, Err: pj.err}
+	}
+	value, err := pj.future.Wait()
+	return Result[J, R]{
+		ID:       pj.future.ID(),
+		Job:      pj.job,
+		Value:    value,
+		Err:      err,
+		Duration: pj.future.Duration(),
+	}
+}
+
+// Ordered submits every job read from jobs and emits their results in
+// submission order. Up to window submitted jobs wait behind the oldest
+// unfinished one, which bounds the reorder buffer. The returned channel is
+// closed once jobs is closed and every result has been emitted, or when ctx
+// is done.
+func (p *Pool[J, R]) Ordered(ctx context.Context, jobs <-chan J, window int) <-chan Result[J, R] {
+	if window < 1 {
+		window = 1
+	}
+	out := make(chan Result[J, R])
+
+	// The pending channel is the reorder buffer: it holds submitted jobs in
+	// order and its capacity limits how far ahead submission can run
+	pending := make(chan pendingJob[J, R], window)
+	go p.feed(ctx, jobs, pending)
+
+	go func() {
+		defer close(out)
+		for pj := range pending {
+			if pj.future != nil {
+				select {
+				case <-pj.future.Done():
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- pj.result():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Unordered submits every job read from jobs and emits each result as soon
+// as its job finishes. The returned channel is closed once jobs is closed and
+// every result has been emitted, or when ctx is done.
+func (p *Pool[J, R]) Unordered(ctx context.Context, jobs <-chan J) <-chan Result[J, R] {
+	out := make(chan Result[J, R])
+	pending := make(chan pendingJob[J, R])
+	go p.feed(ctx, jobs, pending)
+
+	go func() {
+		var wg sync.WaitGroup
+		for pj := range pending {
+			wg.Go(func() {
+				if pj.future != nil {
+					select {
+					case <-pj.future.Done():
+					case <-ctx.Done():
+						return
+					}
+				}
+				select {
+				case out <- pj.result():
+				case <-ctx.Done():
+				}
+			})
+		}
+
+		// Close results channel after every waiter has finished
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// feed submits jobs to the pool and forwards them to pending in order. It
+// stops at the first submit error, which is forwarded as a failed job.
+func (p *Pool[J, R]) feed(ctx context.Context, jobs <-chan J, pending chan<- pendingJob[J, R]) {
+	defer close(pending)
+
+	for {
+		var job J
+		select {
+		case j, ok := <-jobs:
+			if !ok {
+				return
+			}
+			job = j
+		case <-ctx.Done():
+			return
+		}
+
+		future, err := p.Submit(ctx, job)
+		select {
+		case pending <- pendingJob[J, R]{job: job, future: future, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
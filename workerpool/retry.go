@@ -0,0 +1,147 @@
+package workerpool
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed jobs are retried
+type RetryPolicy struct {
+	MaxAttempts    int           // total handler calls per job, including the first
+	InitialBackoff time.Duration // delay before the first retry
+	MaxBackoff     time.Duration // upper bound for any delay (0 means no bound)
+	Multiplier     float64       // growth factor between retries (default 2)
+	Jitter         float64       // random +/- fraction applied to each delay, 0..1
+
+	// Retryable decides whether an error is worth retrying. When nil, every
+	// error except panics and errors wrapped with Permanent is retried.
+	Retryable func(error) bool
+}
+
+// WithRetry retries failed jobs according to policy. Retries wait for their
+// backoff outside the workers and are then queued again.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = &policy
+	}
+}
+
+// permanentError marks an error that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the default retry classifier gives up on it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// retryable reports whether a job that failed with err on attempt number
+// attempts should run again
+func (rp *RetryPolicy) retryable(err error, attempts int) bool {
+	if attempts >= rp.MaxAttempts {
+		return false
+	}
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+	var pe *PanicError
+	return !IsPermanent(err) && !errors.As(err, &pe)
+}
+
+// backoff returns the delay before the retry that follows the given attempt
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := rp.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := float64(rp.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if rp.MaxBackoff > 0 {
+		delay = math.Min(delay, float64(rp.MaxBackoff))
+	}
+	if rp.Jitter > 0 {
+		delay *= 1 + rp.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// scheduleRetry queues the task again after its backoff. It returns false
+// when the task should not be retried.
+func (p *Pool[J, R]) scheduleRetry(t *task[J, R]) bool {
+	if p.retry == nil || !p.retry.retryable(t.lastErr, t.attempts) {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isStopping() {
+		return false
+	}
+	// A job rejected by an open circuit waits at least for the cool-down
+	delay := p.retry.backoff(t.attempts)
+	var open *CircuitOpenError
+	if errors.As(t.lastErr, &open) {
+		delay = max(delay, open.RetryAfter)
+	}
+	p.metrics.retried.Add(1)
+	p.retries[t] = p.clock.AfterFunc(delay, func() {
+		p.requeue(t)
+	})
+	return true
+}
+
+// requeue puts a task whose backoff has elapsed back in the queue
+func (p *Pool[J, R]) requeue(t *task[J, R]) {
+	if abandoned := t.abandoned; t.key != "" && abandoned != nil {
+		// The overrun attempt still holds the key; retry once it returns
+		t.abandoned = nil
+		select {
+		case <-abandoned:
+		default:
+			go func() {
+				<-abandoned
+				p.requeue(t)
+			}()
+			return
+		}
+	}
+
+	p.mu.Lock()
+	delete(p.retries, t)
+	if p.done || p.isStopping() {
+		// The pool is shutting down; report the last failure instead, and
+		// give up on the jobs waiting behind it
+		if t.key != "" {
+			p.skipLaneLocked(t.key)
+		}
+		p.checkDrainedLocked()
+		p.mu.Unlock()
+		p.completed.Add(1)
+		p.metrics.finished(t.lastErr)
+		t.future.resolve(*new(R), t.lastErr)
+		return
+	}
+
+	// Retries bypass the queue size limit and the key lane: they were
+	// already admitted once and are still their key's active job
+	p.pushLocked(t, -1)
+	p.mu.Unlock()
+
+	p.wake()
+}
@@ -0,0 +1,355 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrSchedulerStopped is returned when scheduling on a stopped Scheduler
+var ErrSchedulerStopped = errors.New("workerpool: scheduler stopped")
+
+// MissedThreshold is how late a cron run may start before it counts as
+// missed, e.g. because the process was down or asleep
+const MissedThreshold = time.Minute
+
+// maxCatchUp caps how many missed runs MissedRunAll submits at once
+const maxCatchUp = 1000
+
+// MissedRunPolicy decides what a cron job does about runs that were due
+// while the scheduler was not running
+type MissedRunPolicy int
+
+const (
+	MissedSkip    MissedRunPolicy = iota // drop missed runs and wait for the next one
+	MissedRunOnce                        // run once to catch up, however many were missed
+	MissedRunAll                         // run once for every missed time
+)
+
+// String returns a readable name for the policy
+func (m MissedRunPolicy) String() string {
+	switch m {
+	case MissedSkip:
+		return "skip"
+	case MissedRunOnce:
+		return "run-once"
+	case MissedRunAll:
+		return "run-all"
+	default:
+		return "unknown"
+	}
+}
+
+// EntryID identifies a scheduled job
+type EntryID uint64
+
+// CronJob is a job submitted every time a cron expression matches
+type CronJob[J any] struct {
+	Spec    string          // cron expression, see ParseCron
+	Job     J               // job submitted on every run
+	Missed  MissedRunPolicy // what to do about runs missed during downtime
+	LastRun time.Time       // last run before a restart; zero means now
+	Options []SubmitOption  // options passed to Submit on every run
+}
+
+// Fire reports one scheduled run being submitted to the pool
+type Fire[J, R any] struct {
+	Entry     EntryID
+	Job       J
+	Scheduled time.Time  // when the run was due
+	Future    *Future[R] // nil when Err is set
+	Err       error      // error returned by Submit
+}
+
+// Entry describes a scheduled job
+type Entry struct {
+	ID      EntryID
+	Spec    string    // empty for one-shot jobs
+	Next    time.Time // when the job runs next
+	LastRun time.Time // the last time it was due
+}
+
+// entry is a scheduled job
+type entry[J any] struct {
+	id       EntryID
+	job      J
+	opts     []SubmitOption
+	schedule *CronSchedule // nil for one-shot jobs
+	missed   MissedRunPolicy
+	next     time.Time
+	last     time.Time
+}
+
+// Scheduler submits jobs to a pool at a given time or on a cron schedule.
+// It uses the pool's clock, so a pool built WithClock(NewFakeClock(...))
+// can be driven in tests without real sleeping.
+type Scheduler[J, R any] struct {
+	pool   *Pool[J, R]
+	clock  Clock
+	onFire func(Fire[J, R])
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	started bool
+	entries map[EntryID]*entry[J]
+	nextID  EntryID
+	wake    chan struct{}
+}
+
+// NewScheduler creates a scheduler that submits jobs to pool once Start is
+// called. onFire, if not nil, is called from the scheduler goroutine for
+// every run.
+func NewScheduler[J, R any](pool *Pool[J, R], onFire func(Fire[J, R])) *Scheduler[J, R] {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler[J, R]{
+		pool:    pool,
+		clock:   pool.clock,
+		onFire:  onFire,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		entries: make(map[EntryID]*entry[J]),
+		wake:    make(chan struct{}, 1),
+	}
+	return s
+}
+
+// Start starts submitting due jobs. Jobs may be added before or after.
+func (s *Scheduler[J, R]) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.ctx.Err() != nil {
+		return
+	}
+	s.started = true
+	go s.loop()
+}
+
+// At submits job once at runAt. A time in the past runs immediately.
+func (s *Scheduler[J, R]) At(runAt time.Time, job J, opts ...SubmitOption) (EntryID, error) {
+	return s.add(&entry[J]{job: job, opts: opts, next: runAt})
+}
+
+// After submits job once after d has passed
+func (s *Scheduler[J, R]) After(d time.Duration, job J, opts ...SubmitOption) (EntryID, error) {
+	return s.At(s.clock.Now().Add(d), job, opts...)
+}
+
+// Cron submits c.Job every time c.Spec matches. When c.LastRun is set,
+// runs between it and now count as missed and c.Missed decides about them.
+func (s *Scheduler[J, R]) Cron(c CronJob[J]) (EntryID, error) {
+	schedule, err := ParseCron(c.Spec)
+	if err != nil {
+		return 0, err
+	}
+	last := c.LastRun
+	if last.IsZero() {
+		last = s.clock.Now()
+	}
+	next := schedule.Next(last)
+	if next.IsZero() {
+		return 0, fmt.Errorf("workerpool: cron %q never runs", c.Spec)
+	}
+	return s.add(&entry[J]{
+		job:      c.Job,
+		opts:     c.Options,
+		schedule: schedule,
+		missed:   c.Missed,
+		next:     next,
+		last:     last,
+	})
+}
+
+// add registers e and wakes the loop in case it is due before the others
+func (s *Scheduler[J, R]) add(e *entry[J]) (EntryID, error) {
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return 0, ErrSchedulerStopped
+	}
+	s.nextID++
+	e.id = s.nextID
+	s.entries[e.id] = e
+	s.mu.Unlock()
+
+	s.poke()
+	return e.id, nil
+}
+
+// Cancel removes a scheduled job and reports whether it was still scheduled
+func (s *Scheduler[J, R]) Cancel(id EntryID) bool {
+	s.mu.Lock()
+	_, ok := s.entries[id]
+	delete(s.entries, id)
+	s.mu.Unlock()
+
+	s.poke()
+	return ok
+}
+
+// Entries returns the scheduled jobs, soonest first
+func (s *Scheduler[J, R]) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		item := Entry{ID: e.id, Next: e.next, LastRun: e.last}
+		if e.schedule != nil {
+			item.Spec = e.schedule.String()
+		}
+		list = append(list, item)
+	}
+	slices.SortFunc(list, func(a, b Entry) int {
+		return a.Next.Compare(b.Next)
+	})
+	return list
+}
+
+// Stop stops scheduling new runs and waits for the scheduler goroutine to
+// exit. A Submit blocked on a full queue is cancelled. The pool is left open.
+func (s *Scheduler[J, R]) Stop() {
+	s.mu.Lock()
+	s.cancel()
+	started := s.started
+	s.mu.Unlock()
+
+	if started {
+		<-s.done
+	}
+}
+
+// poke wakes the loop without blocking
+func (s *Scheduler[J, R]) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run is one submission planned by the loop
+type run[J any] struct {
+	entry     EntryID
+	job       J
+	opts      []SubmitOption
+	scheduled time.Time
+}
+
+// loop sleeps until the next job is due, then submits every due job
+func (s *Scheduler[J, R]) loop() {
+	defer close(s.done)
+
+	for {
+		now := s.clock.Now()
+		runs, next := s.due(now)
+
+		// Submitting in the loop keeps catch-up runs in order and lets a
+		// full queue delay the schedule instead of piling up goroutines
+		for _, r := range runs {
+			future, err := s.pool.Submit(s.ctx, r.job, r.opts...)
+			if s.onFire != nil {
+				s.onFire(Fire[J, R]{Entry: r.entry, Job: r.job, Scheduled: r.scheduled, Future: future, Err: err})
+			}
+		}
+
+		// Sleep until the next job is due or the entries change
+		var timer Timer
+		var timeout <-chan time.Time
+		if !next.IsZero() {
+			timer = s.clock.NewTimer(next.Sub(now))
+			timeout = timer.C()
+		}
+		select {
+		case <-timeout:
+		case <-s.wake:
+		case <-s.ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// due collects the runs that are due at now, reschedules cron entries and
+// returns the next time any entry is due (zero if there is none)
+func (s *Scheduler[J, R]) due(now time.Time) ([]run[J], time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []run[J]
+	var next time.Time
+	for _, id := range slices.Sorted(maps.Keys(s.entries)) {
+		e := s.entries[id]
+		if e.next.After(now) {
+			if next.IsZero() || e.next.Before(next) {
+				next = e.next
+			}
+			continue
+		}
+
+		if e.schedule == nil {
+			runs = append(runs, run[J]{entry: e.id, job: e.job, opts: e.opts, scheduled: e.next})
+			delete(s.entries, id)
+			continue
+		}
+
+		for _, t := range e.pending(now) {
+			runs = append(runs, run[J]{entry: e.id, job: e.job, opts: e.opts, scheduled: t})
+		}
+		if e.next.IsZero() {
+			delete(s.entries, id)
+			continue
+		}
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+
+	slices.SortStableFunc(runs, func(a, b run[J]) int {
+		return a.scheduled.Compare(b.scheduled)
+	})
+	return runs, next
+}
+
+// pending returns the times e should run for at now according to its
+// missed-run policy, and advances e past now
+func (e *entry[J]) pending(now time.Time) []time.Time {
+	// Every time the schedule matched since it last ran
+	var due []time.Time
+	t := e.next
+	for !t.IsZero() && !t.After(now) {
+		if len(due) < maxCatchUp {
+			due = append(due, t)
+		}
+		t = e.schedule.Next(t)
+	}
+	e.next = t
+	e.last = due[len(due)-1]
+
+	// Runs that are not too late always happen
+	var onTime, missed []time.Time
+	for _, t := range due {
+		if now.Sub(t) > MissedThreshold {
+			missed = append(missed, t)
+		} else {
+			onTime = append(onTime, t)
+		}
+	}
+	switch {
+	case e.missed == MissedRunAll:
+		return due
+	case e.missed == MissedRunOnce && len(onTime) == 0:
+		return missed[len(missed)-1:]
+	default:
+		return onTime
+	}
+}
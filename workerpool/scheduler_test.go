@@ -0,0 +1,202 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestScheduler starts a scheduler on a pool driven by a fake clock at
+// epoch. Every run it submits is sent to the returned channel.
+func newTestScheduler(t *testing.T) (*FakeClock, *Scheduler[string, string], <-chan Fire[string, string]) {
+	t.Helper()
+	clock := NewFakeClock(epoch)
+	pool := New(2, func(ctx context.Context, job string) (string, error) {
+		return job, nil
+	}, WithClock(clock), WithQueueSize(16))
+	fires := make(chan Fire[string, string], 16)
+	s := NewScheduler(pool, func(f Fire[string, string]) { fires <- f })
+	t.Cleanup(func() {
+		s.Stop()
+		pool.Close()
+	})
+	return clock, s, fires
+}
+
+// nextFire returns the next run the scheduler submitted
+func nextFire(t *testing.T, fires <-chan Fire[string, string]) Fire[string, string] {
+	t.Helper()
+	select {
+	case f := <-fires:
+		if f.Err != nil {
+			t.Fatalf("submit of %s: %v", f.Job, f.Err)
+		}
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("no run was submitted")
+		return Fire[string, string]{}
+	}
+}
+
+// noFire fails the test if a run was submitted. Call it once the scheduler
+// is asleep again, i.e. after clock.BlockUntil(1).
+func noFire(t *testing.T, fires <-chan Fire[string, string]) {
+	t.Helper()
+	select {
+	case f := <-fires:
+		t.Fatalf("unexpected run of %s scheduled at %v", f.Job, f.Scheduled)
+	default:
+	}
+}
+
+func TestSchedulerAtAndAfter(t *testing.T) {
+	clock, s, fires := newTestScheduler(t)
+	if _, err := s.At(epoch.Add(time.Hour), "at"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.After(30*time.Minute, "after"); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+
+	clock.BlockUntil(1)
+	clock.Advance(30*time.Minute - time.Second)
+	clock.BlockUntil(1)
+	noFire(t, fires)
+
+	clock.Advance(time.Second)
+	if f := nextFire(t, fires); f.Job != "after" || !f.Scheduled.Equal(epoch.Add(30*time.Minute)) {
+		t.Fatalf("first run %s at %v, want after at +30m", f.Job, f.Scheduled)
+	}
+	clock.BlockUntil(1)
+	noFire(t, fires)
+
+	clock.Advance(30 * time.Minute)
+	f := nextFire(t, fires)
+	if f.Job != "at" || !f.Scheduled.Equal(epoch.Add(time.Hour)) {
+		t.Fatalf("second run %s at %v, want at at +1h", f.Job, f.Scheduled)
+	}
+	if value, err := f.Future.Wait(); err != nil || value != "at" {
+		t.Fatalf("result = %q, %v; want %q, nil", value, err, "at")
+	}
+	if entries := s.Entries(); len(entries) != 0 {
+		t.Fatalf("entries after both ran = %+v, want none", entries)
+	}
+}
+
+func TestSchedulerAtInThePastRunsNow(t *testing.T) {
+	_, s, fires := newTestScheduler(t)
+	s.Start()
+	if _, err := s.At(epoch.Add(-time.Hour), "late"); err != nil {
+		t.Fatal(err)
+	}
+	if f := nextFire(t, fires); f.Job != "late" {
+		t.Fatalf("ran %s, want late", f.Job)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	clock, s, fires := newTestScheduler(t)
+	a, _ := s.After(time.Minute, "a")
+	b, _ := s.After(2*time.Minute, "b")
+	s.Start()
+
+	if !s.Cancel(a) {
+		t.Fatal("Cancel of a scheduled job returned false")
+	}
+	if s.Cancel(a) {
+		t.Fatal("second Cancel returned true")
+	}
+	if entries := s.Entries(); len(entries) != 1 || entries[0].ID != b {
+		t.Fatalf("entries = %+v, want only b", entries)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Minute)
+	if f := nextFire(t, fires); f.Job != "b" {
+		t.Fatalf("ran %s, want b", f.Job)
+	}
+	noFire(t, fires)
+	if s.Cancel(b) {
+		t.Fatal("Cancel of a job that already ran returned true")
+	}
+}
+
+func TestSchedulerMissedRuns(t *testing.T) {
+	hour := func(n int) time.Time { return epoch.Add(time.Duration(n) * time.Hour) }
+	for _, tc := range []struct {
+		missed MissedRunPolicy
+		want   []time.Time // runs submitted after the jump
+	}{
+		{MissedSkip, nil},
+		{MissedRunOnce, []time.Time{hour(5)}},
+		{MissedRunAll, []time.Time{hour(1), hour(2), hour(3), hour(4), hour(5)}},
+	} {
+		t.Run(tc.missed.String(), func(t *testing.T) {
+			clock, s, fires := newTestScheduler(t)
+			if _, err := s.Cron(CronJob[string]{Spec: "@hourly", Job: "hourly", Missed: tc.missed}); err != nil {
+				t.Fatal(err)
+			}
+			s.Start()
+
+			// The machine sleeps through five runs
+			clock.BlockUntil(1)
+			clock.Advance(5*time.Hour + 30*time.Minute)
+			var got []time.Time
+			for range tc.want {
+				got = append(got, nextFire(t, fires).Scheduled)
+			}
+			clock.BlockUntil(1)
+			noFire(t, fires)
+			if !slices.EqualFunc(got, tc.want, time.Time.Equal) {
+				t.Fatalf("runs after the jump %v, want %v", got, tc.want)
+			}
+			if e := s.Entries()[0]; !e.Next.Equal(hour(6)) || !e.LastRun.Equal(hour(5)) {
+				t.Fatalf("entry next %v, last %v; want +6h, +5h", e.Next, e.LastRun)
+			}
+
+			// Back on schedule
+			clock.Advance(30 * time.Minute)
+			if f := nextFire(t, fires); !f.Scheduled.Equal(hour(6)) {
+				t.Fatalf("next run at %v, want +6h", f.Scheduled)
+			}
+		})
+	}
+}
+
+func TestSchedulerMissedSinceLastRun(t *testing.T) {
+	// Restarted two and a half hours after the last run; the run due just
+	// now is on time, the two before it were missed
+	clock, s, fires := newTestScheduler(t)
+	clock.Advance(30 * time.Second)
+	if _, err := s.Cron(CronJob[string]{
+		Spec:    "@hourly",
+		Job:     "hourly",
+		Missed:  MissedSkip,
+		LastRun: epoch.Add(-3 * time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	if f := nextFire(t, fires); !f.Scheduled.Equal(epoch) {
+		t.Fatalf("ran at %v, want the on-time run at %v", f.Scheduled, epoch)
+	}
+	clock.BlockUntil(1)
+	noFire(t, fires)
+}
+
+func TestSchedulerRejectsBadCron(t *testing.T) {
+	_, s, _ := newTestScheduler(t)
+	if _, err := s.Cron(CronJob[string]{Spec: "61 * * * *"}); err == nil {
+		t.Fatal("Cron accepted an invalid spec")
+	}
+	if _, err := s.Cron(CronJob[string]{Spec: "0 0 31 2 *"}); err == nil {
+		t.Fatal("Cron accepted a spec that never runs")
+	}
+	s.Stop()
+	if _, err := s.After(time.Second, "late"); !errors.Is(err, ErrSchedulerStopped) {
+		t.Fatalf("After on a stopped scheduler: err = %v, want %v", err, ErrSchedulerStopped)
+	}
+}
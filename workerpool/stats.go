@@ -0,0 +1,103 @@
+package workerpool
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of a pool
+type Stats struct {
+	// Job counters since the pool was created
+	Submitted int // calls to Submit
+	Completed int // jobs that finished without an error
+	Failed    int // jobs that finished with an error after their last attempt
+	Retried   int // retries scheduled
+
+	Workers    int           // live worker goroutines
+	Busy       int           // workers currently running a job
+	QueueDepth int           // jobs waiting for a worker
+	LatencyP95 time.Duration // 95th percentile of recent job durations
+	ScaleUps   int           // workers added by the autoscaler
+	ScaleDowns int           // idle workers retired by the autoscaler
+	Replaced   int           // workers replaced after a job overran its timeout
+
+	// Overflow policy outcomes
+	Blocked    int // submits that had to wait for room in the queue
+	Rejected   int // submits refused with ErrQueueFull
+	Dropped    int // jobs discarded by drop-oldest or drop-newest
+	CallerRuns int // jobs run on the submitting goroutine
+
+	Deduplicated int // submits that joined a job with the same idempotency key
+
+	Tenants  map[string]TenantStats  // per-tenant queues, nil without WithTenants
+	Circuits map[string]CircuitStats // circuit breakers, nil without WithBreaker
+
+	WorkerBusy map[int]time.Duration // total time each worker spent running jobs
+	Latency    Histogram             // distribution of job durations
+}
+
+// Stats returns a snapshot of the pool's current state
+func (p *Pool[J, R]) Stats() Stats {
+	p.workersMu.Lock()
+	workers, ups, downs, replaced := p.workers, p.scaleUps, p.scaleDowns, p.replaced
+	p.workersMu.Unlock()
+
+	return Stats{
+		Submitted:    int(p.metrics.submitted.Load()),
+		Completed:    int(p.metrics.succeeded.Load()),
+		Failed:       int(p.metrics.failed.Load()),
+		Retried:      int(p.metrics.retried.Load()),
+		Workers:      workers,
+		Busy:         int(p.busy.Load()),
+		QueueDepth:   p.queueLen(),
+		LatencyP95:   p.latency.percentile(0.95),
+		ScaleUps:     ups,
+		ScaleDowns:   downs,
+		Replaced:     replaced,
+		Blocked:      int(p.blocked.Load()),
+		Rejected:     int(p.rejected.Load()),
+		Dropped:      int(p.dropped.Load()),
+		CallerRuns:   int(p.callerRuns.Load()),
+		Deduplicated: int(p.deduplicated.Load()),
+		Tenants:      p.Tenants(),
+		Circuits:     p.Circuits(),
+		WorkerBusy:   p.metrics.workerBusy(),
+		Latency:      p.metrics.latency(),
+	}
+}
+
+// latencyWindowSize is how many recent job durations are kept for percentiles
+const latencyWindowSize = 256
+
+// latencyWindow keeps the most recent job durations in a ring buffer
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [latencyWindowSize]time.Duration
+	next    int
+	count   int
+}
+
+// record adds one job duration, overwriting the oldest once the buffer is full
+func (w *latencyWindow) record(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+	w.count = min(w.count+1, latencyWindowSize)
+}
+
+// percentile returns the q-th percentile (0 < q <= 1) of the recorded durations
+func (w *latencyWindow) percentile(q float64) time.Duration {
+	w.mu.Lock()
+	sorted := slices.Clone(w.samples[:w.count])
+	w.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	slices.Sort(sorted)
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(idx, len(sorted)-1))]
+}
@@ -0,0 +1,213 @@
+package workerpool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WithWorkStealing gives every worker its own deque instead of one shared
+// queue. Workers take jobs from their own deque without touching the pool
+// lock and steal from the others when it runs dry, which helps with many
+// tiny jobs. Jobs submitted from inside a handler go to that worker's deque.
+// Priorities and aging are ignored in this mode.
+func WithWorkStealing() Option {
+	return func(c *config) {
+		c.stealing = true
+	}
+}
+
+// deque is one worker's queue. The owner takes the oldest task from the
+// front; a thief takes the newer half from the back in one go.
+type deque[J, R any] struct {
+	mu    sync.Mutex
+	items []*task[J, R]
+	head  int // items before head have been taken
+}
+
+// pushBack appends t. The caller must hold mu.
+func (d *deque[J, R]) pushBack(t *task[J, R]) {
+	if d.head > 0 && d.head == len(d.items) {
+		// Reuse the backing array once it is empty
+		clear(d.items)
+		d.items, d.head = d.items[:0], 0
+	}
+	d.items = append(d.items, t)
+}
+
+// popFront takes the oldest task. The caller must hold mu.
+func (d *deque[J, R]) popFront() (*task[J, R], bool) {
+	if d.head == len(d.items) {
+		return nil, false
+	}
+	t := d.items[d.head]
+	d.items[d.head] = nil
+	d.head++
+	return t, true
+}
+
+// size returns the number of tasks. The caller must hold mu.
+func (d *deque[J, R]) size() int {
+	return len(d.items) - d.head
+}
+
+// stealingQueue is the queue of a work-stealing pool: one deque per worker.
+// Unlike the other queues it is safe for concurrent use, so workers can use
+// it without the pool mutex.
+type stealingQueue[J, R any] struct {
+	deques []*deque[J, R]
+	count  atomic.Int64
+	rr     atomic.Uint64 // round-robin cursor for tasks from outside the pool
+}
+
+func newStealingQueue[J, R any](workers int) *stealingQueue[J, R] {
+	q := &stealingQueue[J, R]{deques: make([]*deque[J, R], workers)}
+	for i := range q.deques {
+		q.deques[i] = &deque[J, R]{}
+	}
+	return q
+}
+
+// home returns the deque of a worker. Replaced and autoscaled workers get
+// new IDs, so several may share one deque.
+func (q *stealingQueue[J, R]) home(worker int) *deque[J, R] {
+	return q.deques[worker%len(q.deques)]
+}
+
+func (q *stealingQueue[J, R]) push(t *task[J, R]) {
+	q.pushTo(q.deques[q.rr.Add(1)%uint64(len(q.deques))], t)
+}
+
+// pushLocal queues t on the worker's own deque, or round-robin when the
+// caller is not a worker
+func (q *stealingQueue[J, R]) pushLocal(worker int, t *task[J, R]) {
+	if worker < 0 {
+		q.push(t)
+		return
+	}
+	q.pushTo(q.home(worker), t)
+}
+
+func (q *stealingQueue[J, R]) pushTo(d *deque[J, R], t *task[J, R]) {
+	d.mu.Lock()
+	d.pushBack(t)
+	d.mu.Unlock()
+	q.count.Add(1)
+}
+
+func (q *stealingQueue[J, R]) pop() (*task[J, R], bool) {
+	return q.popFor(int(q.rr.Add(1) % uint64(len(q.deques))))
+}
+
+// popFor takes a task from the worker's own deque, or steals one
+func (q *stealingQueue[J, R]) popFor(worker int) (*task[J, R], bool) {
+	if q.count.Load() == 0 {
+		return nil, false
+	}
+
+	own := q.home(worker)
+	own.mu.Lock()
+	t, ok := own.popFront()
+	own.mu.Unlock()
+	if ok {
+		q.count.Add(-1)
+		return t, true
+	}
+	return q.steal(own, worker)
+}
+
+// steal visits the other deques, starting after the worker's own, and
+// moves half of the first non-empty one to own. It returns one task to run.
+func (q *stealingQueue[J, R]) steal(own *deque[J, R], worker int) (*task[J, R], bool) {
+	n := len(q.deques)
+	for i := 1; i < n; i++ {
+		victim := q.deques[(worker+i)%n]
+		if victim == own {
+			continue
+		}
+
+		victim.mu.Lock()
+		size := victim.size()
+		if size == 0 {
+			victim.mu.Unlock()
+			continue
+		}
+
+		// Take the newer half; the victim keeps the older tasks it would
+		// run next anyway
+		take := (size + 1) / 2
+		cut := len(victim.items) - take
+		loot := make([]*task[J, R], take)
+		copy(loot, victim.items[cut:])
+		clear(victim.items[cut:])
+		victim.items = victim.items[:cut]
+		victim.mu.Unlock()
+
+		if len(loot) > 1 {
+			own.mu.Lock()
+			for _, t := range loot[1:] {
+				own.pushBack(t)
+			}
+			own.mu.Unlock()
+		}
+		q.count.Add(-1)
+		return loot[0], true
+	}
+	return nil, false
+}
+
+func (q *stealingQueue[J, R]) popOldest() (*task[J, R], bool) {
+	// Hold every deque so the oldest task cannot be stolen meanwhile
+	for _, d := range q.deques {
+		d.mu.Lock()
+	}
+	defer func() {
+		for _, d := range q.deques {
+			d.mu.Unlock()
+		}
+	}()
+
+	// The front of each deque is its oldest task
+	var oldest *deque[J, R]
+	for _, d := range q.deques {
+		if d.size() == 0 {
+			continue
+		}
+		if oldest == nil || d.items[d.head].future.id < oldest.items[oldest.head].future.id {
+			oldest = d
+		}
+	}
+	if oldest == nil {
+		return nil, false
+	}
+	t, _ := oldest.popFront()
+	q.count.Add(-1)
+	return t, true
+}
+
+func (q *stealingQueue[J, R]) len() int {
+	return int(q.count.Load())
+}
+
+// nextStealing is next for work-stealing pools. It takes no pool lock
+// unless a submitter is waiting for room or the pool is draining.
+func (p *Pool[J, R]) nextStealing(id int) (*task[J, R], bool) {
+	if p.isStopping() {
+		return nil, false
+	}
+
+	// Count the task as in flight before it leaves the queue, so the pool
+	// never looks drained while a task is between the two
+	p.inflight.Add(1)
+	t, ok := p.steal.popFor(id)
+	if !ok {
+		p.release()
+		return nil, false
+	}
+
+	if p.spaceWaiters.Load() > 0 {
+		p.mu.Lock()
+		p.signalSpaceLocked()
+		p.mu.Unlock()
+	}
+	return t, true
+}
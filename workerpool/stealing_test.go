@@ -0,0 +1,129 @@
+package workerpool
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// benchJob carries its submit time so the handler can measure queueing +
+// run time
+type benchJob struct {
+	index     int
+	work      time.Duration
+	submitted time.Time
+}
+
+// benchMixes decide how long job i runs
+var benchMixes = []struct {
+	name string
+	work func(i int) time.Duration
+}{
+	{"short", func(i int) time.Duration { return time.Microsecond }},
+	{"spiky", func(i int) time.Duration {
+		// Mostly short jobs with an occasional long one
+		if i%20 == 0 {
+			return 200 * time.Microsecond
+		}
+		return time.Microsecond
+	}},
+	{"long", func(i int) time.Duration {
+		// Mostly long jobs of uneven length with an occasional short one
+		if i%10 == 0 {
+			return time.Microsecond
+		}
+		return time.Duration(100+i%4*100) * time.Microsecond
+	}},
+}
+
+// spin burns CPU for d, like a real handler would, instead of sleeping
+func spin(d time.Duration) {
+	start := time.Now()
+	for time.Since(start) < d {
+	}
+}
+
+// benchRunner processes jobs with one design and records each job's latency
+type benchRunner func(workers int, jobs []benchJob, latency []time.Duration)
+
+// channelRunner is the original RunWorkers design: workers range over one
+// shared buffered channel
+func channelRunner(workers int, jobs []benchJob, latency []time.Duration) {
+	ch := make(chan benchJob, 2*workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for j := range ch {
+				spin(j.work)
+				latency[j.index] = time.Since(j.submitted)
+			}
+		})
+	}
+	for _, j := range jobs {
+		j.submitted = time.Now()
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+}
+
+// poolRunner returns a runner backed by a pool with the given options
+func poolRunner(opts ...Option) benchRunner {
+	return func(workers int, jobs []benchJob, latency []time.Duration) {
+		opts := append([]Option{WithQueueSize(2 * workers)}, opts...)
+		pool := New(workers, func(ctx context.Context, j benchJob) (struct{}, error) {
+			spin(j.work)
+			latency[j.index] = time.Since(j.submitted)
+			return struct{}{}, nil
+		}, opts...)
+
+		ctx := context.Background()
+		for _, j := range jobs {
+			j.submitted = time.Now()
+			pool.Submit(ctx, j)
+		}
+		pool.Close()
+	}
+}
+
+// benchmarkMixes runs every mix through run with one worker per CPU and
+// reports the latency percentiles next to ns/op
+func benchmarkMixes(b *testing.B, run benchRunner) {
+	workers := runtime.GOMAXPROCS(0)
+	for _, m := range benchMixes {
+		b.Run(m.name, func(b *testing.B) {
+			b.ReportAllocs()
+			jobs := make([]benchJob, b.N)
+			for i := range jobs {
+				jobs[i] = benchJob{index: i, work: m.work(i)}
+			}
+			latency := make([]time.Duration, b.N)
+			b.ResetTimer()
+			run(workers, jobs, latency)
+			b.StopTimer()
+
+			slices.Sort(latency)
+			at := func(q float64) float64 {
+				return float64(latency[int(q*float64(len(latency)-1))])
+			}
+			b.ReportMetric(at(0.50), "p50-ns")
+			b.ReportMetric(at(0.99), "p99-ns")
+			b.ReportMetric(at(1), "max-ns")
+		})
+	}
+}
+
+func BenchmarkChannel(b *testing.B) {
+	benchmarkMixes(b, channelRunner)
+}
+
+func BenchmarkSharedQueue(b *testing.B) {
+	benchmarkMixes(b, poolRunner())
+}
+
+func BenchmarkWorkStealing(b *testing.B) {
+	benchmarkMixes(b, poolRunner(WithWorkStealing()))
+}
@@ -0,0 +1,99 @@
+package workerpool
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Map runs fn over jobs on a pool of numWorkers workers and yields the
+// results in the order of jobs:
+//
+//	for value, err := range workerpool.Map(ctx, 4, slices.Values(urls), fetch) {
+//		...
+//	}
+//
+// Breaking out of the loop stops reading jobs and cancels the jobs still
+// queued or running. Every goroutine Map started, including the pool's
+// workers, has exited by the time the loop ends. When ctx is cancelled
+// before every result was yielded, the last pair carries ctx's error.
+// opts configure the pool as for New.
+func Map[J, R any](ctx context.Context, numWorkers int, jobs iter.Seq[J], fn Handler[J, R], opts ...Option) iter.Seq2[R, error] {
+	return stream(ctx, numWorkers, jobs, fn, opts, func(p *Pool[J, R], ctx context.Context, in <-chan J) <-chan Result[J, R] {
+		return p.Ordered(ctx, in, 2*numWorkers)
+	})
+}
+
+// Stream is Map with each result yielded as soon as its job finishes,
+// regardless of the order of jobs
+func Stream[J, R any](ctx context.Context, numWorkers int, jobs iter.Seq[J], fn Handler[J, R], opts ...Option) iter.Seq2[R, error] {
+	return stream(ctx, numWorkers, jobs, fn, opts, func(p *Pool[J, R], ctx context.Context, in <-chan J) <-chan Result[J, R] {
+		return p.Unordered(ctx, in)
+	})
+}
+
+// stream runs one pass of Map or Stream; collect turns the job channel into
+// results with the pool's Ordered or Unordered
+func stream[J, R any](parent context.Context, numWorkers int, jobs iter.Seq[J], fn Handler[J, R], opts []Option,
+	collect func(*Pool[J, R], context.Context, <-chan J) <-chan Result[J, R]) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		ctx, cancel := context.WithCancel(parent)
+		pool := New(numWorkers, fn, opts...)
+
+		// Feed the jobs through a channel, counting them
+		in := make(chan J)
+		var sent int
+		var exhausted bool
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			defer close(in)
+			for job := range jobs {
+				select {
+				case in <- job:
+					sent++
+				case <-ctx.Done():
+					return
+				}
+			}
+			exhausted = true
+		})
+		results := collect(pool, ctx, in)
+
+		// Stop everything on the way out, however the loop ended
+		complete := false
+		defer func() {
+			cancel()
+			for range results {
+				// Wait for the collector goroutines to exit
+			}
+			wg.Wait()
+			if complete {
+				pool.Close()
+				return
+			}
+			// Cancel the jobs that are still running
+			pool.Shutdown(ctx)
+		}()
+
+		yielded := 0
+		for r := range results {
+			yielded++
+			if !yield(r.Value, r.Err) {
+				return
+			}
+		}
+
+		// The results ended on their own: all jobs done, a submit failed, or
+		// ctx was cancelled
+		cancel()
+		wg.Wait()
+		if exhausted && yielded == sent {
+			complete = true
+			return
+		}
+		if err := context.Cause(parent); err != nil {
+			var zero R
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// naturals yields 0, 1, 2, ... forever
+func naturals() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// square returns job*job after a short random-ish delay, so jobs finish out
+// of order
+func square(ctx context.Context, job int) (int, error) {
+	if err := Sleep(ctx, time.Duration(job%3)*time.Millisecond); err != nil {
+		return 0, err
+	}
+	return job * job, nil
+}
+
+// checkNoLeak fails the test if the goroutine count does not come back to
+// before. Goroutines that already returned may take a moment to be reaped.
+func checkNoLeak(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutines: %d before, %d after\n%s", before, runtime.NumGoroutine(), buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMapKeepsOrder(t *testing.T) {
+	var got []int
+	for value, err := range Map(context.Background(), 4, slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8}), square) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, value)
+	}
+	if want := []int{1, 4, 9, 16, 25, 36, 49, 64}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestStreamYieldsEveryResult(t *testing.T) {
+	var got []int
+	for value, err := range Stream(context.Background(), 4, slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8}), square) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, value)
+	}
+	slices.Sort(got)
+	if want := []int{1, 4, 9, 16, 25, 36, 49, 64}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestBreakReleasesGoroutines(t *testing.T) {
+	for name, run := range map[string]func(context.Context, int, iter.Seq[int], Handler[int, int], ...Option) iter.Seq2[int, error]{
+		"Map":    Map[int, int],
+		"Stream": Stream[int, int],
+	} {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			n := 0
+			for _, err := range run(context.Background(), 8, naturals(), square) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n++; n == 20 {
+					break
+				}
+			}
+			checkNoLeak(t, before)
+		})
+	}
+}
+
+func TestBreakCancelsRunningJobs(t *testing.T) {
+	before := runtime.NumGoroutine()
+	started := make(chan struct{}, 3)
+	cancelled := make(chan struct{}, 3)
+	block := func(ctx context.Context, job int) (int, error) {
+		if job == 0 {
+			// Yield once jobs 1-3 are running
+			for range 3 {
+				<-started
+			}
+			return 0, nil
+		}
+		if job > 3 {
+			// Took the worker of job 0
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		started <- struct{}{}
+		<-ctx.Done()
+		cancelled <- struct{}{}
+		return 0, ctx.Err()
+	}
+	for range Stream(context.Background(), 4, naturals(), block) {
+		break // Job 0; jobs 1-3 are still running
+	}
+	if got := len(cancelled); got != 3 {
+		t.Fatalf("cancelled jobs = %d, want 3", got)
+	}
+	checkNoLeak(t, before)
+}
+
+func TestParentCancelEndsWithCause(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var last error
+	n := 0
+	for _, err := range Map(ctx, 4, naturals(), square) {
+		if n++; n == 10 {
+			cancel()
+		}
+		last = err
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Fatalf("last error = %v, want %v", last, context.Canceled)
+	}
+	checkNoLeak(t, before)
+}
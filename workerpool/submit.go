@@ -0,0 +1,32 @@
+package workerpool
+
+import "time"
+
+// SubmitOption configures a single submitted job
+type SubmitOption func(*submitConfig)
+
+// submitConfig holds the settings collected from SubmitOptions
+type submitConfig struct {
+	priority       int
+	timeout        time.Duration
+	key            string
+	idempotencyKey string
+	tenant         string
+	circuit        string
+}
+
+// WithPriority sets the job's priority (default PriorityNormal). Queued jobs
+// with a higher priority are handed to workers first.
+func WithPriority(priority int) SubmitOption {
+	return func(c *submitConfig) {
+		c.priority = priority
+	}
+}
+
+// WithTimeout sets a deadline for each attempt of this job, overriding the
+// pool's WithJobTimeout
+func WithTimeout(d time.Duration) SubmitOption {
+	return func(c *submitConfig) {
+		c.timeout = d
+	}
+}
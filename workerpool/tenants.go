@@ -0,0 +1,205 @@
+package workerpool
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// DefaultTenant is the tenant of jobs submitted without WithTenant
+const DefaultTenant = "default"
+
+// WithTenants replaces the single queue with one queue per tenant, served by
+// deficit round-robin: in every round each tenant with waiting jobs gets as
+// many jobs dispatched as its weight, so a tenant that floods the pool only
+// delays the others by its share. Tenants missing from weights, including
+// DefaultTenant, get weight 1. Priorities still order the jobs within a
+// tenant. This turns off work stealing.
+//
+// The queue size limit applies to all tenants together, so give the pool a
+// queue large enough for the bursts you expect.
+func WithTenants(weights map[string]int) Option {
+	return func(c *config) {
+		c.tenants = maps.Clone(weights)
+		if c.tenants == nil {
+			c.tenants = make(map[string]int)
+		}
+	}
+}
+
+// WithTenant tags the job with the tenant it is queued under
+// (default DefaultTenant); see WithTenants
+func WithTenant(tenant string) SubmitOption {
+	return func(c *submitConfig) {
+		c.tenant = tenant
+	}
+}
+
+// TenantStats is a snapshot of one tenant's queue
+type TenantStats struct {
+	Weight     int
+	Queued     int           // jobs waiting for a worker
+	Dispatched int           // jobs handed to a worker, retries included
+	Wait       time.Duration // total time dispatched jobs spent queued
+	OldestWait time.Duration // how long the oldest waiting job has been queued
+}
+
+// AvgWait returns the average time a dispatched job spent queued
+func (s TenantStats) AvgWait() time.Duration {
+	if s.Dispatched == 0 {
+		return 0
+	}
+	return s.Wait / time.Duration(s.Dispatched)
+}
+
+// Tenants returns a snapshot of every tenant seen so far, or nil when the
+// pool was not created with WithTenants
+func (p *Pool[J, R]) Tenants() map[string]TenantStats {
+	if p.fair == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fair.stats()
+}
+
+// tenantQueue holds the waiting jobs of one tenant
+type tenantQueue[J, R any] struct {
+	name       string
+	weight     int
+	deficit    int // jobs the tenant may still dispatch in its current turn
+	tasks      *priorityQueue[J, R]
+	active     bool // in the round-robin ring
+	dispatched int
+	wait       time.Duration
+}
+
+// fairQueue is the queue of a pool with tenants. Tenants with waiting jobs
+// sit in a ring; the tenant at the cursor dispatches up to its weight in
+// jobs, then the cursor moves on. Every job costs one unit of deficit.
+type fairQueue[J, R any] struct {
+	tenants map[string]*tenantQueue[J, R]
+	weights map[string]int
+	aging   time.Duration
+	since   func() time.Duration // the clock enqueuedAt is measured on
+
+	ring   []*tenantQueue[J, R] // tenants with waiting jobs, in turn order
+	cursor int
+	n      int
+}
+
+func newFairQueue[J, R any](weights map[string]int, aging time.Duration, since func() time.Duration) *fairQueue[J, R] {
+	return &fairQueue[J, R]{
+		tenants: make(map[string]*tenantQueue[J, R]),
+		weights: weights,
+		aging:   aging,
+		since:   since,
+	}
+}
+
+// tenant returns the queue of name, creating it on first use
+func (q *fairQueue[J, R]) tenant(name string) *tenantQueue[J, R] {
+	if name == "" {
+		name = DefaultTenant
+	}
+	tq, ok := q.tenants[name]
+	if !ok {
+		tq = &tenantQueue[J, R]{name: name, weight: max(q.weights[name], 1), tasks: newPriorityQueue[J, R](q.aging)}
+		q.tenants[name] = tq
+	}
+	return tq
+}
+
+func (q *fairQueue[J, R]) push(t *task[J, R]) {
+	tq := q.tenant(t.tenant)
+	tq.tasks.push(t)
+	q.n++
+	if !tq.active {
+		// A tenant that was idle joins the end of the round
+		tq.active = true
+		tq.deficit = 0
+		q.ring = append(q.ring, tq)
+	}
+}
+
+func (q *fairQueue[J, R]) pop() (*task[J, R], bool) {
+	if q.n == 0 {
+		return nil, false
+	}
+	tq := q.ring[q.cursor]
+	if tq.deficit <= 0 {
+		tq.deficit += tq.weight // A new turn
+	}
+	t, _ := tq.tasks.pop()
+	tq.deficit--
+	tq.dispatched++
+	tq.wait += q.since() - t.enqueuedAt
+	q.n--
+
+	switch {
+	case tq.tasks.len() == 0:
+		q.deactivate(q.cursor)
+	case tq.deficit == 0:
+		q.cursor = (q.cursor + 1) % len(q.ring)
+	}
+	return t, true
+}
+
+// deactivate takes the tenant at ring index i out of the round
+func (q *fairQueue[J, R]) deactivate(i int) {
+	q.ring[i].active = false
+	q.ring[i].deficit = 0
+	q.ring = slices.Delete(q.ring, i, i+1)
+	if i < q.cursor {
+		q.cursor--
+	}
+	if q.cursor >= len(q.ring) {
+		q.cursor = 0
+	}
+}
+
+func (q *fairQueue[J, R]) popOldest() (*task[J, R], bool) {
+	if q.n == 0 {
+		return nil, false
+	}
+	oldest := -1
+	var oldestID uint64
+	for i, tq := range q.ring {
+		for _, t := range tq.tasks.tasks.items {
+			if oldest < 0 || t.future.id < oldestID {
+				oldest, oldestID = i, t.future.id
+			}
+		}
+	}
+
+	tq := q.ring[oldest]
+	t, _ := tq.tasks.popOldest()
+	q.n--
+	if tq.tasks.len() == 0 {
+		q.deactivate(oldest)
+	}
+	return t, true
+}
+
+func (q *fairQueue[J, R]) len() int {
+	return q.n
+}
+
+// stats returns a snapshot of every tenant. The caller must hold the pool's mu.
+func (q *fairQueue[J, R]) stats() map[string]TenantStats {
+	now := q.since()
+	stats := make(map[string]TenantStats, len(q.tenants))
+	for name, tq := range q.tenants {
+		s := TenantStats{
+			Weight:     tq.weight,
+			Queued:     tq.tasks.len(),
+			Dispatched: tq.dispatched,
+			Wait:       tq.wait,
+		}
+		for _, t := range tq.tasks.tasks.items {
+			s.OldestWait = max(s.OldestWait, now-t.enqueuedAt)
+		}
+		stats[name] = s
+	}
+	return stats
+}
@@ -0,0 +1,86 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// submitTenant queues n jobs named tenant-0, tenant-1, ... for tenant
+func (op *orderPool) submitTenant(t *testing.T, tenant string, n int) {
+	t.Helper()
+	for i := range n {
+		if _, err := op.Submit(context.Background(), fmt.Sprintf("%s-%d", tenant, i), WithTenant(tenant)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// tenantsOf maps each job name in order to its tenant
+func tenantsOf(order []string) []string {
+	tenants := make([]string, len(order))
+	for i, job := range order {
+		tenants[i], _, _ = strings.Cut(job, "-")
+	}
+	return tenants
+}
+
+func TestTenantsDispatchByWeight(t *testing.T) {
+	op := newOrderPool(t, WithQueueSize(200), WithTenants(map[string]int{"heavy": 5, "medium": 2}))
+	// The heavy tenant floods the queue before the others show up; light
+	// has no weight of its own and gets 1
+	op.submitTenant(t, "heavy", 100)
+	op.submitTenant(t, "medium", 40)
+	op.submitTenant(t, "light", 20)
+	stats := op.Tenants()
+	tenants := tenantsOf(op.order())
+
+	// While all three have jobs waiting, every round of 8 dispatches gives
+	// each its weight, in the order they joined
+	round := strings.Fields("heavy heavy heavy heavy heavy medium medium light")
+	for start := 0; start+len(round) <= 20*len(round); start += len(round) {
+		got := tenants[start : start+len(round)]
+		if strings.Join(got, " ") != strings.Join(round, " ") {
+			t.Fatalf("round at %d dispatched %v, want %v", start, got, round)
+		}
+	}
+	if s := stats["light"]; s.Weight != 1 || s.Queued != 20 {
+		t.Fatalf("light before dispatch: %+v", s)
+	}
+	final := op.Tenants()
+	for name, want := range map[string]int{"heavy": 100, "medium": 40, "light": 20} {
+		if got := final[name].Dispatched; got != want {
+			t.Errorf("%s dispatched %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestTenantsLightTenantNotStarved(t *testing.T) {
+	op := newOrderPool(t, WithQueueSize(1100), WithTenants(map[string]int{"heavy": 10}))
+	op.submitTenant(t, "heavy", 1000)
+	op.submitTenant(t, "light", 10)
+	tenants := tenantsOf(op.order())
+
+	// The light tenant waits at most one heavy turn between its jobs, not
+	// behind the heavy tenant's whole backlog
+	gap, lights, lastLight := 0, 0, 0
+	for i, tenant := range tenants {
+		if tenant == "heavy" {
+			gap++
+			continue
+		}
+		if gap > 10 {
+			t.Fatalf("light job %d dispatched after %d heavy jobs in a row", lights, gap)
+		}
+		gap, lastLight = 0, i
+		lights++
+	}
+	if lights != 10 {
+		t.Fatalf("%d light jobs dispatched, want 10", lights)
+	}
+	// Ten turns of 10 heavy and 1 light
+	if lastLight >= 110 {
+		t.Fatalf("last light job dispatched %dth, want within the first 110", lastLight+1)
+	}
+}